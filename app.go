@@ -14,6 +14,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 )
 
 // App holds facebook application information.
@@ -24,6 +25,9 @@ type App struct {
 	// Facebook app secret
 	AppSecret string
 
+	// Facebook app client token. It's required in device login.
+	ClientToken string
+
 	// Facebook app redirect URI in the app's configuration.
 	RedirectUri string
 
@@ -31,9 +35,30 @@ type App struct {
 	// Facebook document: https://developers.facebook.com/docs/graph-api/securing-requests
 	EnableAppsecretProof bool
 
+	// The store to create and verify CSRF state in login flow.
+	// If it's not set, states are signed by AppSecret with HMACStateStore.
+	StateStore StateStore
+
+	// The max age of a signed request accepted by DecodeSignedRequest.
+	// If it's 0, the age of signed request is not checked.
+	SignedRequestMaxAge time.Duration
+
+	// The allowed clock skew when DecodeSignedRequest validates issued_at and expires.
+	// If it's 0, DefaultSignedRequestClockSkew is used. Set it to a negative value to disallow any skew.
+	SignedRequestClockSkew time.Duration
+
+	// The key set to verify Limited Login id_token in VerifyIDToken.
+	// If it's not set, keys are fetched from DefaultJWKSURL and cached.
+	IDTokenKeySet KeySet
+
+	// The allowed clock skew when VerifyIDToken validates exp.
+	IDTokenClockSkew time.Duration
+
 	// The session to send request when parsing tokens or code.
 	// If it's not set, default session will be used.
 	session *Session
+
+	remoteKeySet *RemoteKeySet // default key set of IDTokenKeySet.
 }
 
 // New creates a new App and sets app id and secret.
@@ -116,8 +141,8 @@ func (app *App) ParseSignedRequest(signedRequest string) (res Result, err error)
 // It's a shorthand call to ParseCodeInfo(code, "").
 //
 // In facebook PHP SDK, there is a CSRF state to avoid attack.
-// That state is not checked in this library.
-// Caller is responsible to store and check state if possible.
+// That state is not checked in ParseCode.
+// Use ParseLoginCallback or VerifyState to check state before redeeming code.
 func (app *App) ParseCode(code string) (token string, err error) {
 	token, _, _, err = app.ParseCodeInfo(code, "")
 	return
@@ -128,21 +153,19 @@ func (app *App) ParseCode(code string) (token string, err error) {
 //
 // See https://developers.facebook.com/docs/facebook-login/access-tokens#extending
 func (app *App) ParseCodeInfo(code, machineID string) (token string, expires int, newMachineID string, err error) {
+	return app.parseCodeInfo(code, machineID, "")
+}
+
+func (app *App) parseCodeInfo(code, machineID, codeVerifier string) (token string, expires int, newMachineID string, err error) {
 	if code == "" {
 		err = fmt.Errorf("facebook: code is empty")
 		return
 	}
 
 	var res Result
-	res, err = app.session.sendOauthRequest("/oauth/access_token", Params{
-		"client_id":     app.AppId,
-		"redirect_uri":  app.RedirectUri,
-		"client_secret": app.AppSecret,
-		"code":          code,
-	})
+	res, err = app.redeemCode(code, codeVerifier)
 
 	if err != nil {
-		err = fmt.Errorf("facebook: fail to parse facebook response with error %w", err)
 		return
 	}
 
@@ -173,6 +196,31 @@ func (app *App) ParseCodeInfo(code, machineID string) (token string, expires int
 	return
 }
 
+func (app *App) redeemCode(code, codeVerifier string) (Result, error) {
+	params := Params{
+		"client_id":    app.AppId,
+		"redirect_uri": app.RedirectUri,
+		"code":         code,
+	}
+
+	// public clients using PKCE don't have app secret.
+	if app.AppSecret != "" {
+		params["client_secret"] = app.AppSecret
+	}
+
+	if codeVerifier != "" {
+		params["code_verifier"] = codeVerifier
+	}
+
+	res, err := app.getSession().sendOauthRequest("/oauth/access_token", params)
+
+	if err != nil {
+		return nil, fmt.Errorf("facebook: fail to parse facebook response with error %w", err)
+	}
+
+	return res, nil
+}
+
 // ExchangeToken exchanges a short-lived access token to a long-lived access token.
 // Return new access token and its expires time.
 func (app *App) ExchangeToken(accessToken string) (token string, expires int, err error) {
@@ -182,15 +230,9 @@ func (app *App) ExchangeToken(accessToken string) (token string, expires int, er
 	}
 
 	var res Result
-	res, err = app.session.sendOauthRequest("/oauth/access_token", Params{
-		"grant_type":        "fb_exchange_token",
-		"client_id":         app.AppId,
-		"client_secret":     app.AppSecret,
-		"fb_exchange_token": accessToken,
-	})
+	res, err = app.exchangeToken(accessToken)
 
 	if err != nil {
-		err = fmt.Errorf("fail to parse facebook response with error %w", err)
 		return
 	}
 
@@ -213,6 +255,21 @@ func (app *App) ExchangeToken(accessToken string) (token string, expires int, er
 	return
 }
 
+func (app *App) exchangeToken(accessToken string) (Result, error) {
+	res, err := app.getSession().sendOauthRequest("/oauth/access_token", Params{
+		"grant_type":        "fb_exchange_token",
+		"client_id":         app.AppId,
+		"client_secret":     app.AppSecret,
+		"fb_exchange_token": accessToken,
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("fail to parse facebook response with error %w", err)
+	}
+
+	return res, nil
+}
+
 // GetCode gets code from a long lived access token.
 // Return the code retrieved from facebook.
 func (app *App) GetCode(accessToken string) (code string, err error) {
@@ -222,7 +279,7 @@ func (app *App) GetCode(accessToken string) (code string, err error) {
 	}
 
 	var res Result
-	res, err = app.session.sendOauthRequest("/oauth/client_code", Params{
+	res, err = app.getSession().sendOauthRequest("/oauth/client_code", Params{
 		"client_id":     app.AppId,
 		"client_secret": app.AppSecret,
 		"redirect_uri":  app.RedirectUri,
@@ -240,11 +297,12 @@ func (app *App) GetCode(accessToken string) (code string, err error) {
 
 // Session creates a session based on current App setting.
 func (app *App) Session(accessToken string) *Session {
-	return &Session{
-		accessToken:          accessToken,
+	session := &Session{
 		app:                  app,
 		enableAppsecretProof: app.EnableAppsecretProof,
 	}
+	session.SetAccessToken(accessToken)
+	return session
 }
 
 // SessionFromSignedRequest creates a session from a signed request.
@@ -265,12 +323,9 @@ func (app *App) SessionFromSignedRequest(signedRequest string) (session *Session
 	err = res.DecodeField("oauth_token", &token)
 
 	if err == nil {
-		session = &Session{
-			accessToken:          token,
-			app:                  app,
-			id:                   id,
-			enableAppsecretProof: app.EnableAppsecretProof,
-		}
+		session = app.Session("")
+		session.SetToken(newTokenFromSignedRequest(res, token))
+		session.state().id = id
 		return
 	}
 
@@ -289,11 +344,7 @@ func (app *App) SessionFromSignedRequest(signedRequest string) (session *Session
 		return
 	}
 
-	session = &Session{
-		accessToken:          token,
-		app:                  app,
-		id:                   id,
-		enableAppsecretProof: app.EnableAppsecretProof,
-	}
+	session = app.Session(token)
+	session.state().id = id
 	return
 }
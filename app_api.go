@@ -0,0 +1,204 @@
+// A facebook graph api client in go.
+// https://github.com/huandu/facebook/
+//
+// Copyright 2012, Huan Du
+// Licensed under the MIT license
+// https://github.com/huandu/facebook/blob/master/LICENSE
+
+package facebook
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AppSubscription is a webhooks subscription of an app.
+type AppSubscription struct {
+	Object      string                 // object type, e.g. "page", "user" or "instagram".
+	CallbackURL string                 `facebook:"callback_url"`
+	Active      bool                   // whether the subscription is active.
+	Fields      []AppSubscriptionField // subscribed fields.
+}
+
+// AppSubscriptionField is a subscribed field in AppSubscription.
+type AppSubscriptionField struct {
+	Name    string
+	Version string
+}
+
+// AppRole is a role of a user in an app.
+type AppRole struct {
+	AppID string `facebook:"app_id"`
+	User  string // user id.
+	Role  string // role name, e.g. "administrators", "developers", "testers" or "insights users".
+}
+
+// TestUser is a test user of an app.
+// Email and Password are only returned when a test user is created.
+type TestUser struct {
+	ID          string
+	AccessToken string
+	LoginURL    string `facebook:"login_url"`
+	Email       string
+	Password    string
+}
+
+// FetchAppAccessToken gets an app access token from facebook through client_credentials grant.
+//
+// Unlike AppAccessToken, which concatenates AppId and AppSecret, FetchAppAccessToken
+// asks facebook for a token which doesn't contain app secret.
+//
+// See https://developers.facebook.com/docs/facebook-login/guides/access-tokens#apptokens
+func (app *App) FetchAppAccessToken() (*Token, error) {
+	if app.AppSecret == "" {
+		return nil, fmt.Errorf("facebook: app secret is required to fetch app access token")
+	}
+
+	res, err := app.getSession().sendOauthRequest("/oauth/access_token", Params{
+		"client_id":     app.AppId,
+		"client_secret": app.AppSecret,
+		"grant_type":    "client_credentials",
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("facebook: fail to fetch app access token with error %w", err)
+	}
+
+	return newTokenFromResult(res)
+}
+
+// AppSession creates a session with an app access token fetched by FetchAppAccessToken.
+// Appsecret proof is always enabled in the returned session.
+func (app *App) AppSession() (*Session, error) {
+	token, err := app.FetchAppAccessToken()
+
+	if err != nil {
+		return nil, err
+	}
+
+	session := app.SessionFromToken(token)
+	session.EnableAppsecretProof(true)
+	return session, nil
+}
+
+// AppSubscriptions gets all webhooks subscriptions of the app.
+// Session must use an app access token, e.g. a session created by App#AppSession.
+func (session *Session) AppSubscriptions() (subscriptions []AppSubscription, err error) {
+	err = session.decodeAllData("/app/subscriptions", nil, &subscriptions)
+	return
+}
+
+// Subscribe creates or updates a webhooks subscription of the app.
+// The verifyToken is sent back to callbackURL in the verification request.
+// Session must use an app access token.
+func (session *Session) Subscribe(object, callbackURL string, fields []string, verifyToken string) error {
+	_, err := session.Post("/app/subscriptions", Params{
+		"object":       object,
+		"callback_url": callbackURL,
+		"fields":       strings.Join(fields, ","),
+		"verify_token": verifyToken,
+	})
+	return err
+}
+
+// Unsubscribe deletes fields in a webhooks subscription of the app.
+// If fields is empty, the whole subscription of the object is deleted.
+// Session must use an app access token.
+func (session *Session) Unsubscribe(object string, fields []string) error {
+	params := Params{
+		"object": object,
+	}
+
+	if len(fields) != 0 {
+		params["fields"] = strings.Join(fields, ",")
+	}
+
+	_, err := session.Delete("/app/subscriptions", params)
+	return err
+}
+
+// AppRoles gets all user roles of the app.
+// Session must use an app access token.
+func (session *Session) AppRoles() (roles []AppRole, err error) {
+	err = session.decodeAllData("/app/roles", nil, &roles)
+	return
+}
+
+// TestUsers gets all test users of the app.
+// Session must use an app access token.
+func (session *Session) TestUsers() (users []TestUser, err error) {
+	err = session.decodeAllData("/app/accounts/test-users", nil, &users)
+	return
+}
+
+// CreateTestUser creates a test user with name and permissions.
+// If installed is true, the app is installed for the test user with permissions.
+// Session must use an app access token.
+func (session *Session) CreateTestUser(name string, installed bool, permissions []string) (*TestUser, error) {
+	params := Params{
+		"installed": installed,
+	}
+
+	if name != "" {
+		params["name"] = name
+	}
+
+	if len(permissions) != 0 {
+		params["permissions"] = strings.Join(permissions, ",")
+	}
+
+	res, err := session.Post("/app/accounts/test-users", params)
+
+	if err != nil {
+		return nil, err
+	}
+
+	user := &TestUser{}
+
+	if err := res.Decode(user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// DeleteTestUser deletes a test user.
+// Session must use an app access token.
+func (session *Session) DeleteTestUser(id string) error {
+	_, err := session.Delete("/"+id, nil)
+	return err
+}
+
+// decodeAllData reads all pages of a paging api and decodes all items in "data" to v.
+// The v must be a pointer to a slice.
+func (session *Session) decodeAllData(path string, params Params, v interface{}) error {
+	res, err := session.Get(path, params)
+
+	if err != nil {
+		return err
+	}
+
+	pr, err := res.Paging(session)
+
+	if err != nil {
+		return err
+	}
+
+	data := append([]Result{}, pr.Data()...)
+
+	for pr.HasNext() {
+		noMore, err := pr.Next()
+
+		if err != nil {
+			return err
+		}
+
+		data = append(data, pr.Data()...)
+
+		if noMore {
+			break
+		}
+	}
+
+	return Result{"data": data}.DecodeField("data", v)
+}
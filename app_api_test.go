@@ -0,0 +1,135 @@
+// A facebook graph api client in go.
+// https://github.com/huandu/facebook/
+//
+// Copyright 2012, Huan Du
+// Licensed under the MIT license
+// https://github.com/huandu/facebook/blob/master/LICENSE
+
+package facebook
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAppSession(t *testing.T) {
+	var srv *httptest.Server
+	testMux := http.NewServeMux()
+	testMux.HandleFunc("/v3.0/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+
+		if r.Form.Get("grant_type") != "client_credentials" || r.Form.Get("client_secret") != "secret" {
+			t.Errorf("invalid client_credentials params. [form:%v]", r.Form)
+		}
+
+		w.Write([]byte(`{"access_token":"app-token","token_type":"bearer"}`))
+	})
+	testMux.HandleFunc("/v3.0/app/accounts/test-users", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+
+		// facebook keeps access token in paging urls. there is no need to check them.
+		if r.Form.Get("after") == "" && (r.Form.Get("access_token") != "app-token" || r.Form.Get("appsecret_proof") == "") {
+			t.Errorf("app session must use app token with appsecret proof. [form:%v]", r.Form)
+		}
+
+		if r.Method == "POST" {
+			if r.Form.Get("installed") != "true" || r.Form.Get("permissions") != "email,public_profile" {
+				t.Errorf("invalid test user params. [form:%v]", r.Form)
+			}
+
+			w.Write([]byte(`{"id":"3","access_token":"user-token","login_url":"https://login","email":"a@b.c","password":"pass"}`))
+			return
+		}
+
+		if r.Form.Get("after") == "" {
+			fmt.Fprintf(w, `{"data":[{"id":"1","access_token":"t1"}],"paging":{"next":"%v/v3.0/app/accounts/test-users?after=1"}}`, srv.URL)
+			return
+		}
+
+		w.Write([]byte(`{"data":[{"id":"2","access_token":"t2"}]}`))
+	})
+	testMux.HandleFunc("/v3.0/app/subscriptions", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":[{"object":"page","callback_url":"https://example.com/webhook","active":true,"fields":[{"name":"feed","version":"v3.0"}]}]}`))
+	})
+	testMux.HandleFunc("/v3.0/app/roles", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":[{"app_id":"123","user":"456","role":"administrators"}]}`))
+	})
+
+	srv = httptest.NewServer(testMux)
+	defer srv.Close()
+
+	app := New("123", "secret")
+	app.SetSession(&Session{
+		Version: "v3.0",
+		BaseURL: srv.URL + "/",
+	})
+
+	session, err := app.AppSession()
+
+	if err != nil {
+		t.Fatalf("fail to create app session. [e:%v]", err)
+	}
+
+	if session.AccessToken() != "app-token" || session.AppsecretProof() == "" {
+		t.Fatalf("app session must use fetched token and appsecret proof. [token:%v]", session.AccessToken())
+	}
+
+	session.Version = "v3.0"
+	session.BaseURL = srv.URL + "/"
+
+	users, err := session.TestUsers()
+
+	if err != nil {
+		t.Fatalf("fail to get test users. [e:%v]", err)
+	}
+
+	if len(users) != 2 || users[0].ID != "1" || users[1].AccessToken != "t2" {
+		t.Fatalf("invalid test users. [users:%v]", users)
+	}
+
+	user, err := session.CreateTestUser("", true, []string{"email", "public_profile"})
+
+	if err != nil || user.ID != "3" || user.Password != "pass" || user.LoginURL != "https://login" {
+		t.Fatalf("fail to create test user. [user:%v] [e:%v]", user, err)
+	}
+
+	subscriptions, err := session.AppSubscriptions()
+
+	if err != nil || len(subscriptions) != 1 || !subscriptions[0].Active || subscriptions[0].Fields[0].Name != "feed" {
+		t.Fatalf("fail to get subscriptions. [subscriptions:%v] [e:%v]", subscriptions, err)
+	}
+
+	roles, err := session.AppRoles()
+
+	if err != nil || len(roles) != 1 || roles[0].Role != "administrators" || roles[0].AppID != "123" {
+		t.Fatalf("fail to get roles. [roles:%v] [e:%v]", roles, err)
+	}
+}
+
+func TestAppLiteralUsesDefaultSession(t *testing.T) {
+	client := DefaultHttpClient()
+	SetHttpClient(&http.Client{
+		Transport: alwaysFailRoundTripper{},
+	})
+	defer SetHttpClient(client)
+
+	// an App literal has no session. it must fall back to default session instead of panicking.
+	app := &App{
+		AppId:     "123",
+		AppSecret: "secret",
+	}
+
+	if _, err := app.FetchAppAccessToken(); err == nil {
+		t.Fatalf("request must fail with alwaysFailRoundTripper.")
+	}
+
+	if _, err := app.ParseCode("code"); err == nil {
+		t.Fatalf("request must fail with alwaysFailRoundTripper.")
+	}
+
+	if _, err := app.ExchangeLongLivedToken("token"); err == nil {
+		t.Fatalf("request must fail with alwaysFailRoundTripper.")
+	}
+}
@@ -0,0 +1,353 @@
+// A facebook graph api client in go.
+// https://github.com/huandu/facebook/
+//
+// Copyright 2012, Huan Du
+// Licensed under the MIT license
+// https://github.com/huandu/facebook/blob/master/LICENSE
+
+// Command fbgen generates Go structs from recorded Graph API metadata.
+//
+// Record metadata of a node with metadata=1, e.g.
+//
+//	fbgraph get /me metadata=1 > user.json
+//
+// and generate structs with facebook tags and field constants.
+//
+//	fbgen -package models -o models.go user.json page.json
+//
+// Generated structs can be decoded by facebook.Result#Decode.
+// Fields referring to other generated node types use pointers to them.
+// Fields of unknown object types are decoded as facebook.Result.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Exit codes.
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+// common initialisms in Go names.
+var initialisms = map[string]string{
+	"api":  "API",
+	"id":   "ID",
+	"ids":  "IDs",
+	"url":  "URL",
+	"urls": "URLs",
+	"uri":  "URI",
+	"http": "HTTP",
+	"ip":   "IP",
+	"json": "JSON",
+	"html": "HTML",
+	"ig":   "IG",
+	"fb":   "FB",
+}
+
+var (
+	regexpListType = regexp.MustCompile(`^list<(.+)>$`)
+	regexpNonWord  = regexp.MustCompile(`[^A-Za-z0-9]+`)
+)
+
+// node is the metadata of a node type.
+type node struct {
+	Name        string // Go type name.
+	Type        string // graph api node type, e.g. "page".
+	Source      string // the metadata file.
+	Fields      []nodeField
+	Connections []string
+}
+
+type nodeField struct {
+	Name        string
+	Type        string
+	Description string
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("fbgen", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	pkg := flags.String("package", "models", "package name of generated file")
+	output := flags.String("o", "", "output file. Write to stdout if it's empty")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: fbgen [flags] METADATA_FILE...")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	var nodes []*node
+
+	for _, file := range flags.Args() {
+		n, err := loadNode(file)
+
+		if err != nil {
+			fmt.Fprintln(stderr, "fbgen:", err)
+			return exitError
+		}
+
+		nodes = append(nodes, n)
+	}
+
+	src, err := generate(*pkg, nodes)
+
+	if err != nil {
+		fmt.Fprintln(stderr, "fbgen:", err)
+		return exitError
+	}
+
+	if *output == "" {
+		stdout.Write(src)
+		return exitOK
+	}
+
+	if err = os.WriteFile(*output, src, 0644); err != nil {
+		fmt.Fprintln(stderr, "fbgen:", err)
+		return exitError
+	}
+
+	return exitOK
+}
+
+// loadNode reads a metadata file recorded from a graph api response with metadata=1.
+func loadNode(file string) (*node, error) {
+	data, err := os.ReadFile(file)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var res struct {
+		Metadata *struct {
+			Type   string `json:"type"`
+			Fields []struct {
+				Name        string `json:"name"`
+				Type        string `json:"type"`
+				Description string `json:"description"`
+			} `json:"fields"`
+			Connections map[string]interface{} `json:"connections"`
+		} `json:"metadata"`
+	}
+
+	if err = json.Unmarshal(data, &res); err != nil {
+		return nil, fmt.Errorf("cannot parse %v; %w", file, err)
+	}
+
+	if res.Metadata == nil || res.Metadata.Type == "" {
+		return nil, fmt.Errorf("metadata or its type is missing in %v; record it with metadata=1", file)
+	}
+
+	n := &node{
+		Name:   goName(res.Metadata.Type),
+		Type:   res.Metadata.Type,
+		Source: file,
+	}
+	seen := map[string]bool{}
+
+	for _, f := range res.Metadata.Fields {
+		if f.Name == "" || seen[f.Name] {
+			continue
+		}
+
+		seen[f.Name] = true
+		n.Fields = append(n.Fields, nodeField{
+			Name:        f.Name,
+			Type:        f.Type,
+			Description: f.Description,
+		})
+	}
+
+	for name := range res.Metadata.Connections {
+		n.Connections = append(n.Connections, name)
+	}
+
+	sort.Strings(n.Connections)
+	return n, nil
+}
+
+// generate generates Go source of nodes.
+func generate(pkg string, nodes []*node) ([]byte, error) {
+	known := map[string]string{}
+
+	for _, n := range nodes {
+		if other, ok := known[strings.ToLower(n.Name)]; ok {
+			return nil, fmt.Errorf("node type %v is defined more than once", other)
+		}
+
+		known[strings.ToLower(n.Name)] = n.Name
+	}
+
+	buf := &bytes.Buffer{}
+	usesResult := false
+	body := &bytes.Buffer{}
+
+	for _, n := range nodes {
+		fmt.Fprintf(body, "\n// %v is a graph api %v node generated from %v.\n", n.Name, n.Type, filepath.Base(n.Source))
+		fmt.Fprintf(body, "type %v struct {\n", n.Name)
+
+		for _, f := range n.Fields {
+			typ := goType(f.Type, known)
+
+			if strings.Contains(typ, "facebook.Result") {
+				usesResult = true
+			}
+
+			fmt.Fprintf(body, "%v %v `facebook:\"%v\"`", goName(f.Name), typ, f.Name)
+
+			if comment := shortDescription(f.Description); comment != "" {
+				fmt.Fprintf(body, " // %v", comment)
+			}
+
+			body.WriteString("\n")
+		}
+
+		body.WriteString("}\n")
+
+		fmt.Fprintf(body, "\n// Fields of %v.\nconst (\n", n.Name)
+
+		for _, f := range n.Fields {
+			fmt.Fprintf(body, "%vField%v = %q\n", n.Name, goName(f.Name), f.Name)
+		}
+
+		body.WriteString(")\n")
+
+		fmt.Fprintf(body, "\n// %vFields is all fields of %v.\nvar %vFields = []string{\n", n.Name, n.Name, n.Name)
+
+		for _, f := range n.Fields {
+			fmt.Fprintf(body, "%vField%v,\n", n.Name, goName(f.Name))
+		}
+
+		body.WriteString("}\n")
+
+		if len(n.Connections) != 0 {
+			fmt.Fprintf(body, "\n// Edges of %v.\nconst (\n", n.Name)
+
+			for _, c := range n.Connections {
+				fmt.Fprintf(body, "%vEdge%v = %q\n", n.Name, goName(c), c)
+			}
+
+			body.WriteString(")\n")
+		}
+	}
+
+	buf.WriteString("// Code generated by fbgen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(buf, "package %v\n", pkg)
+
+	if usesResult {
+		buf.WriteString("\nimport \"github.com/huandu/facebook/v2\"\n")
+	}
+
+	buf.Write(body.Bytes())
+	src, err := format.Source(buf.Bytes())
+
+	if err != nil {
+		return nil, fmt.Errorf("fail to format generated code; %w", err)
+	}
+
+	return src, nil
+}
+
+// goType converts a graph api field type to Go type.
+func goType(typ string, known map[string]string) string {
+	typ = strings.TrimSpace(typ)
+
+	if m := regexpListType.FindStringSubmatch(typ); m != nil {
+		elem := goType(m[1], known)
+		return "[]" + strings.TrimPrefix(elem, "*")
+	}
+
+	switch {
+	case typ == "string", typ == "numeric string", typ == "id", typ == "token", typ == "datetime",
+		strings.HasPrefix(typ, "enum"):
+		return "string"
+	case typ == "bool":
+		return "bool"
+	case typ == "float", typ == "double", typ == "decimal":
+		return "float64"
+	case strings.HasPrefix(typ, "unsigned int"):
+		return "uint64"
+	case strings.HasPrefix(typ, "int"):
+		return "int64"
+	}
+
+	if name, ok := known[strings.ToLower(goName(typ))]; ok {
+		return "*" + name
+	}
+
+	return "facebook.Result"
+}
+
+// goName converts a snake_case or space separated name to an exported Go name.
+func goName(name string) string {
+	words := regexpNonWord.Split(name, -1)
+	buf := &strings.Builder{}
+
+	for _, word := range words {
+		if word == "" {
+			continue
+		}
+
+		// keep CamelCase words as is, e.g. "PageCategory".
+		if unicode.IsUpper(rune(word[0])) {
+			buf.WriteString(word)
+			continue
+		}
+
+		if initialism, ok := initialisms[strings.ToLower(word)]; ok {
+			buf.WriteString(initialism)
+			continue
+		}
+
+		buf.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+
+	s := buf.String()
+
+	if s == "" || unicode.IsDigit(rune(s[0])) {
+		s = "X" + s
+	}
+
+	return s
+}
+
+// shortDescription returns the first sentence of description in one line.
+func shortDescription(description string) string {
+	description = strings.Join(strings.Fields(description), " ")
+
+	if i := strings.Index(description, ". "); i >= 0 {
+		description = description[:i+1]
+	}
+
+	const maxLen = 100
+
+	if len(description) > maxLen {
+		description = strings.TrimSpace(description[:maxLen]) + "..."
+	}
+
+	return description
+}
@@ -0,0 +1,170 @@
+// A facebook graph api client in go.
+// https://github.com/huandu/facebook/
+//
+// Copyright 2012, Huan Du
+// Licensed under the MIT license
+// https://github.com/huandu/facebook/blob/master/LICENSE
+
+package main
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/huandu/facebook/v2"
+)
+
+func runTest(t *testing.T, args ...string) (code int, stdout, stderr string) {
+	t.Helper()
+
+	outBuf := &bytes.Buffer{}
+	errBuf := &bytes.Buffer{}
+	code = run(args, outBuf, errBuf)
+	return code, outBuf.String(), errBuf.String()
+}
+
+func TestGenerate(t *testing.T) {
+	code, stdout, stderr := runTest(t, "-package=models", "testdata/page.json", "testdata/business.json")
+
+	if code != exitOK {
+		t.Fatalf("fbgen must succeed. [code:%v] [stderr:%v]", code, stderr)
+	}
+
+	if _, err := parser.ParseFile(token.NewFileSet(), "models.go", stdout, parser.AllErrors); err != nil {
+		t.Fatalf("generated code must be valid go source. [e:%v] [src:%v]", err, stdout)
+	}
+
+	for _, expected := range []string{
+		"// Code generated by fbgen. DO NOT EDIT.",
+		"package models",
+		`import "github.com/huandu/facebook/v2"`,
+		"// Page is a graph api page node generated from page.json.",
+		"type Page struct {",
+		"ID                 string            `facebook:\"id\"`",
+		"FanCount           uint64            `facebook:\"fan_count\"`",
+		"OverallStarRating  float64           `facebook:\"overall_star_rating\"`",
+		"Emails             []string          `facebook:\"emails\"`",
+		"OwnerBusiness      *Business         `facebook:\"owner_business\"`",
+		"Location           facebook.Result   `facebook:\"location\"`",
+		"CategoryList       []facebook.Result `facebook:\"category_list\"`",
+		"// Information about the Page.\n",
+		"PageFieldFanCount           = \"fan_count\"",
+		"var PageFields = []string{",
+		"PageEdgeFeed   = \"feed\"",
+		"PrimaryPage       *Page  `facebook:\"primary_page\"`",
+		"ProfilePictureURI string `facebook:\"profile_picture_uri\"`",
+		"BusinessEdgeOwnedPages = \"owned_pages\"",
+	} {
+		if !strings.Contains(stdout, expected) {
+			t.Fatalf("generated code must contain %q. [src:%v]", expected, stdout)
+		}
+	}
+
+	if strings.Count(stdout, "`facebook:\"name\"`") != 2 {
+		t.Fatalf("duplicated fields must be removed. [src:%v]", stdout)
+	}
+}
+
+func TestGenerateOutputFile(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "business.go")
+	code, stdout, stderr := runTest(t, "-package=biz", "-o", output, "testdata/business.json")
+
+	if code != exitOK || stdout != "" {
+		t.Fatalf("fbgen must write to output file. [code:%v] [stdout:%v] [stderr:%v]", code, stdout, stderr)
+	}
+
+	src, err := os.ReadFile(output)
+
+	if err != nil {
+		t.Fatalf("fail to read output file. [e:%v]", err)
+	}
+
+	// Page is not generated so primary_page falls back to facebook.Result.
+	if !strings.Contains(string(src), "package biz") || !strings.Contains(string(src), "PrimaryPage       facebook.Result `facebook:\"primary_page\"`") {
+		t.Fatalf("invalid generated code. [src:%s]", src)
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	if code, _, _ := runTest(t); code != exitUsage {
+		t.Fatalf("metadata file is required. [code:%v]", code)
+	}
+
+	if code, _, stderr := runTest(t, "testdata/invalid.json"); code != exitError || !strings.Contains(stderr, "metadata=1") {
+		t.Fatalf("file without metadata must be rejected. [code:%v] [stderr:%v]", code, stderr)
+	}
+
+	if code, _, stderr := runTest(t, "testdata/page.json", "testdata/page.json"); code != exitError || !strings.Contains(stderr, "more than once") {
+		t.Fatalf("duplicated node types must be rejected. [code:%v] [stderr:%v]", code, stderr)
+	}
+
+	if code, _, _ := runTest(t, "testdata/not_exist.json"); code != exitError {
+		t.Fatalf("missing file must be rejected. [code:%v]", code)
+	}
+}
+
+func TestGoName(t *testing.T) {
+	for name, expected := range map[string]string{
+		"id":                  "ID",
+		"fan_count":           "FanCount",
+		"profile_picture_uri": "ProfilePictureURI",
+		"ig_user_ids":         "IGUserIDs",
+		"PageCategory":        "PageCategory",
+		"page":                "Page",
+		"3d_photo":            "X3dPhoto",
+	} {
+		if actual := goName(name); actual != expected {
+			t.Fatalf("invalid go name. [name:%v] [expected:%v] [actual:%v]", name, expected, actual)
+		}
+	}
+}
+
+// Generated types must be decodable by Result#Decode.
+func TestDecodeGeneratedTypes(t *testing.T) {
+	type business struct {
+		ID   string `facebook:"id"`
+		Name string `facebook:"name"`
+	}
+	type page struct {
+		ID                string            `facebook:"id"`
+		FanCount          uint64            `facebook:"fan_count"`
+		OverallStarRating float64           `facebook:"overall_star_rating"`
+		IsPublished       bool              `facebook:"is_published"`
+		Emails            []string          `facebook:"emails"`
+		OwnerBusiness     *business         `facebook:"owner_business"`
+		Location          facebook.Result   `facebook:"location"`
+		CategoryList      []facebook.Result `facebook:"category_list"`
+	}
+
+	res, err := facebook.MakeResult([]byte(`{
+		"id": "123",
+		"fan_count": 42,
+		"overall_star_rating": 4.5,
+		"is_published": true,
+		"emails": ["a@example.com"],
+		"owner_business": {"id": "456", "name": "Biz"},
+		"location": {"city": "Menlo Park"},
+		"category_list": [{"id": "1", "name": "Software"}]
+	}`))
+
+	if err != nil {
+		t.Fatalf("fail to make result. [e:%v]", err)
+	}
+
+	var p page
+
+	if err = res.Decode(&p); err != nil {
+		t.Fatalf("fail to decode. [e:%v]", err)
+	}
+
+	if p.ID != "123" || p.FanCount != 42 || p.OverallStarRating != 4.5 || !p.IsPublished ||
+		len(p.Emails) != 1 || p.OwnerBusiness == nil || p.OwnerBusiness.Name != "Biz" ||
+		p.Location["city"] != "Menlo Park" || len(p.CategoryList) != 1 || p.CategoryList[0]["name"] != "Software" {
+		t.Fatalf("invalid decoded page. [page:%#v]", p)
+	}
+}
@@ -0,0 +1,477 @@
+// A facebook graph api client in go.
+// https://github.com/huandu/facebook/
+//
+// Copyright 2012, Huan Du
+// Licensed under the MIT license
+// https://github.com/huandu/facebook/blob/master/LICENSE
+
+// Command fbgraph is a scriptable Graph API explorer.
+//
+// Usage:
+//
+//	fbgraph [flags] get PATH [key=value...]
+//	fbgraph [flags] post PATH [key=value...]
+//	fbgraph [flags] delete PATH [key=value...]
+//	fbgraph [flags] batch FILE
+//	fbgraph [flags] token inspect [TOKEN]
+//	fbgraph [flags] token exchange TOKEN
+//	fbgraph [flags] signed-request decode SIGNED_REQUEST
+//
+// Results are written to stdout as JSON. With -all-pages, every item of all pages
+// is written as a line of JSON. Usage and debug information are written to stderr.
+//
+// A value starting with "@" in key=value uploads the file, e.g. source=@photo.jpg.
+// Access token, app id and app secret default to environment variables
+// FB_ACCESS_TOKEN, FB_APP_ID and FB_APP_SECRET.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/huandu/facebook/v2"
+)
+
+// Exit codes.
+const (
+	exitOK    = 0
+	exitError = 1 // graph api or io error.
+	exitUsage = 2 // invalid command line.
+)
+
+var errUsage = errors.New("invalid usage")
+
+type command struct {
+	accessToken    string
+	appID          string
+	appSecret      string
+	version        string
+	baseURL        string
+	debug          bool
+	allPages       bool
+	appsecretProof bool
+
+	flags  *flag.FlagSet
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	cmd := &command{
+		stdout: stdout,
+		stderr: stderr,
+	}
+
+	flags := flag.NewFlagSet("fbgraph", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.StringVar(&cmd.accessToken, "token", os.Getenv("FB_ACCESS_TOKEN"), "access token")
+	flags.StringVar(&cmd.appID, "app-id", os.Getenv("FB_APP_ID"), "app id")
+	flags.StringVar(&cmd.appSecret, "app-secret", os.Getenv("FB_APP_SECRET"), "app secret")
+	flags.StringVar(&cmd.version, "version", "", "graph api version, e.g. v19.0")
+	flags.StringVar(&cmd.baseURL, "base-url", "", "graph api base url with trailing slash")
+	flags.BoolVar(&cmd.debug, "debug", false, "print graph api debug messages to stderr")
+	flags.BoolVar(&cmd.allPages, "all-pages", false, "follow paging and print every item as a line of JSON")
+	flags.BoolVar(&cmd.appsecretProof, "appsecret-proof", false, "send appsecret_proof with app secret")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: fbgraph [flags] get|post|delete PATH [key=value...]")
+		fmt.Fprintln(stderr, "       fbgraph [flags] batch FILE")
+		fmt.Fprintln(stderr, "       fbgraph [flags] token inspect [TOKEN]")
+		fmt.Fprintln(stderr, "       fbgraph [flags] token exchange TOKEN")
+		fmt.Fprintln(stderr, "       fbgraph [flags] signed-request decode SIGNED_REQUEST")
+		fmt.Fprintln(stderr, "flags can be mixed with args after command name. args after \"--\" are never parsed as flags.")
+		flags.PrintDefaults()
+	}
+	cmd.flags = flags
+
+	err := cmd.run(args)
+
+	if err == nil {
+		return exitOK
+	}
+
+	if errors.Is(err, errUsage) || errors.Is(err, flag.ErrHelp) {
+		if !errors.Is(err, flag.ErrHelp) && err != errUsage {
+			fmt.Fprintln(stderr, "fbgraph:", err)
+		}
+
+		flags.Usage()
+		return exitUsage
+	}
+
+	fmt.Fprintln(stderr, "fbgraph:", err)
+	return exitError
+}
+
+func (cmd *command) run(args []string) error {
+	if err := cmd.flags.Parse(args); err != nil {
+		return err
+	}
+
+	args = cmd.flags.Args()
+
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	name, args := args[0], args[1:]
+	args, err := cmd.parseArgs(args)
+
+	if err != nil {
+		return err
+	}
+
+	switch name {
+	case "get":
+		return cmd.api(facebook.GET, args)
+	case "post":
+		return cmd.api(facebook.POST, args)
+	case "delete":
+		return cmd.api(facebook.DELETE, args)
+	case "batch":
+		return cmd.batch(args)
+	case "token":
+		return cmd.token(args)
+	case "signed-request":
+		return cmd.signedRequest(args)
+	}
+
+	return fmt.Errorf("%w: unknown command '%v'", errUsage, name)
+}
+
+// parseArgs parses flags mixed with args after command name, e.g. "get /me -all-pages".
+// Args after "--" are never parsed as flags.
+func (cmd *command) parseArgs(args []string) (rest []string, err error) {
+	for {
+		if err = cmd.flags.Parse(args); err != nil {
+			return
+		}
+
+		remaining := cmd.flags.Args()
+		consumed := len(args) - len(remaining)
+
+		if consumed > 0 && args[consumed-1] == "--" {
+			rest = append(rest, remaining...)
+			return
+		}
+
+		if len(remaining) == 0 {
+			return
+		}
+
+		rest = append(rest, remaining[0])
+		args = remaining[1:]
+	}
+}
+
+func (cmd *command) api(method facebook.Method, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: PATH is required", errUsage)
+	}
+
+	path := args[0]
+	params, err := parseParams(args[1:])
+
+	if err != nil {
+		return err
+	}
+
+	session := cmd.session()
+	res, err := session.Api(path, method, params)
+	cmd.printInfo(res)
+
+	if err != nil {
+		return err
+	}
+
+	if !cmd.allPages || method != facebook.GET {
+		return cmd.print(res)
+	}
+
+	pr, err := res.Paging(session)
+
+	if err != nil {
+		return err
+	}
+
+	for {
+		for _, item := range pr.Data() {
+			if err := cmd.printLine(item); err != nil {
+				return err
+			}
+		}
+
+		if !pr.HasNext() {
+			return nil
+		}
+
+		noMore, err := pr.Next()
+
+		if err != nil {
+			return err
+		}
+
+		if usage := pr.UsageInfo(); usage != nil {
+			cmd.printStderr("usage", usage)
+		}
+
+		if noMore {
+			return nil
+		}
+	}
+}
+
+func (cmd *command) batch(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("%w: batch FILE is required", errUsage)
+	}
+
+	data, err := os.ReadFile(args[0])
+
+	if err != nil {
+		return err
+	}
+
+	var ops []facebook.Params
+
+	if err = json.Unmarshal(data, &ops); err != nil {
+		return fmt.Errorf("cannot parse batch file %v; %w", args[0], err)
+	}
+
+	results, err := cmd.session().BatchApi(ops...)
+
+	if err != nil {
+		return err
+	}
+
+	for _, res := range results {
+		if res == nil {
+			if err := cmd.printLine(nil); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		br, err := res.Batch()
+
+		if err != nil {
+			return err
+		}
+
+		line := facebook.Result{
+			"code": br.StatusCode,
+			"body": cleanResult(br.Result),
+		}
+
+		if err := cmd.printLine(line); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (cmd *command) token(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: token inspect or token exchange is required", errUsage)
+	}
+
+	sub, args := args[0], args[1:]
+	token := cmd.accessToken
+
+	if len(args) > 0 {
+		token = args[0]
+	}
+
+	if token == "" {
+		return fmt.Errorf("%w: TOKEN is required", errUsage)
+	}
+
+	app, err := cmd.app()
+
+	if err != nil {
+		return err
+	}
+
+	switch sub {
+	case "inspect":
+		res, err := cmd.configure(app.Session(token)).Inspect()
+
+		if err != nil {
+			return err
+		}
+
+		return cmd.print(res)
+
+	case "exchange":
+		newToken, expires, err := app.ExchangeToken(token)
+
+		if err != nil {
+			return err
+		}
+
+		return cmd.print(facebook.Result{
+			"access_token": newToken,
+			"expires_in":   expires,
+		})
+	}
+
+	return fmt.Errorf("%w: unknown token command '%v'", errUsage, sub)
+}
+
+func (cmd *command) signedRequest(args []string) error {
+	if len(args) != 2 || args[0] != "decode" {
+		return fmt.Errorf("%w: signed-request decode SIGNED_REQUEST is required", errUsage)
+	}
+
+	app, err := cmd.app()
+
+	if err != nil {
+		return err
+	}
+
+	res, err := app.ParseSignedRequest(args[1])
+
+	if err != nil {
+		return err
+	}
+
+	return cmd.print(res)
+}
+
+func (cmd *command) app() (*facebook.App, error) {
+	if cmd.appID == "" || cmd.appSecret == "" {
+		return nil, fmt.Errorf("%w: -app-id and -app-secret are required", errUsage)
+	}
+
+	app := facebook.New(cmd.appID, cmd.appSecret)
+	app.SetSession(cmd.configure(&facebook.Session{}))
+	return app, nil
+}
+
+func (cmd *command) session() *facebook.Session {
+	var session *facebook.Session
+
+	if cmd.appID != "" && cmd.appSecret != "" {
+		app := facebook.New(cmd.appID, cmd.appSecret)
+		app.EnableAppsecretProof = cmd.appsecretProof
+		session = app.Session(cmd.accessToken)
+	} else {
+		session = &facebook.Session{}
+		session.SetAccessToken(cmd.accessToken)
+	}
+
+	return cmd.configure(session)
+}
+
+func (cmd *command) configure(session *facebook.Session) *facebook.Session {
+	session.Version = cmd.version
+	session.BaseURL = cmd.baseURL
+
+	if cmd.debug {
+		session.SetDebug(facebook.DEBUG_ALL)
+	}
+
+	return session
+}
+
+func (cmd *command) print(res facebook.Result) error {
+	enc := json.NewEncoder(cmd.stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(cleanResult(res))
+}
+
+func (cmd *command) printLine(v interface{}) error {
+	if res, ok := v.(facebook.Result); ok {
+		v = cleanResult(res)
+	}
+
+	return json.NewEncoder(cmd.stdout).Encode(v)
+}
+
+// printInfo writes usage and debug information in res to stderr.
+func (cmd *command) printInfo(res facebook.Result) {
+	if res == nil {
+		return
+	}
+
+	if usage := res.UsageInfo(); usage != nil {
+		cmd.printStderr("usage", usage)
+	}
+
+	if debug := res.DebugInfo(); debug != nil {
+		cmd.printStderr("debug", struct {
+			Messages           []facebook.DebugMessage `json:"messages,omitempty"`
+			FacebookApiVersion string                  `json:"facebook_api_version,omitempty"`
+			FacebookDebug      string                  `json:"x_fb_debug,omitempty"`
+			FacebookRev        string                  `json:"x_fb_rev,omitempty"`
+		}{
+			Messages:           debug.Messages,
+			FacebookApiVersion: debug.FacebookApiVersion,
+			FacebookDebug:      debug.FacebookDebug,
+			FacebookRev:        debug.FacebookRev,
+		})
+	}
+}
+
+func (cmd *command) printStderr(name string, v interface{}) {
+	data, err := json.Marshal(v)
+
+	if err != nil {
+		return
+	}
+
+	fmt.Fprintf(cmd.stderr, "%v: %s\n", name, data)
+}
+
+// cleanResult removes usage and debug information from res.
+func cleanResult(res facebook.Result) facebook.Result {
+	if res == nil {
+		return nil
+	}
+
+	cleaned := make(facebook.Result, len(res))
+
+	for k, v := range res {
+		if k == "__usage__" || k == "__debug__" {
+			continue
+		}
+
+		cleaned[k] = v
+	}
+
+	return cleaned
+}
+
+// parseParams parses key=value args. A value starting with "@" is a file to upload.
+func parseParams(args []string) (facebook.Params, error) {
+	params := facebook.Params{}
+
+	for _, arg := range args {
+		i := strings.IndexByte(arg, '=')
+
+		if i <= 0 {
+			return nil, fmt.Errorf("%w: param '%v' must be in the form of key=value", errUsage, arg)
+		}
+
+		key, value := arg[:i], arg[i+1:]
+
+		if strings.HasPrefix(value, "@") && len(value) > 1 {
+			params[key] = facebook.File(value[1:])
+			continue
+		}
+
+		params[key] = value
+	}
+
+	return params, nil
+}
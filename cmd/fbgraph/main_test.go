@@ -0,0 +1,176 @@
+// A facebook graph api client in go.
+// https://github.com/huandu/facebook/
+//
+// Copyright 2012, Huan Du
+// Licensed under the MIT license
+// https://github.com/huandu/facebook/blob/master/LICENSE
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/huandu/facebook/v2"
+	"github.com/huandu/facebook/v2/fbtest"
+)
+
+func runTest(t *testing.T, args ...string) (code int, stdout, stderr string) {
+	t.Helper()
+
+	for _, env := range []string{"FB_ACCESS_TOKEN", "FB_APP_ID", "FB_APP_SECRET"} {
+		t.Setenv(env, "")
+	}
+
+	outBuf := &bytes.Buffer{}
+	errBuf := &bytes.Buffer{}
+	code = run(args, outBuf, errBuf)
+	return code, outBuf.String(), errBuf.String()
+}
+
+func TestAPICommands(t *testing.T) {
+	server := fbtest.NewServer()
+	defer server.Close()
+
+	server.Respond("GET", "/me", facebook.Result{"id": "123", "name": "Jane"})
+	server.Respond("POST", "/me/feed", facebook.Result{"id": "123_1"})
+	server.Respond("DELETE", "/123_1", nil)
+	server.RespondPages("GET", "/me/feed",
+		[]interface{}{facebook.Result{"id": "1"}, facebook.Result{"id": "2"}},
+		[]interface{}{facebook.Result{"id": "3"}},
+	)
+	server.SetUsage(&facebook.UsageInfo{App: facebook.RateLimiting{CallCount: 7}})
+	baseURL := "-base-url=" + server.URL + "/"
+
+	code, stdout, stderr := runTest(t, "-token=token", baseURL, "get", "/me", "fields=id,name")
+
+	if code != exitOK || !strings.Contains(stdout, `"name": "Jane"`) || strings.Contains(stdout, "__usage__") {
+		t.Fatalf("invalid get output. [code:%v] [stdout:%v] [stderr:%v]", code, stdout, stderr)
+	}
+
+	if !strings.Contains(stderr, `usage: {"app":{"call_count":7`) {
+		t.Fatalf("usage must be printed to stderr. [stderr:%v]", stderr)
+	}
+
+	server.LastRequest("GET", "/me").AssertParams(t, facebook.Params{"fields": "id,name"})
+
+	code, stdout, _ = runTest(t, "-token=token", baseURL, "post", "/me/feed", "message=hello")
+
+	if code != exitOK || !strings.Contains(stdout, `"id": "123_1"`) {
+		t.Fatalf("invalid post output. [code:%v] [stdout:%v]", code, stdout)
+	}
+
+	server.LastRequest("POST", "/me/feed").AssertParams(t, facebook.Params{"message": "hello"})
+
+	if code, _, stderr = runTest(t, "-token=token", baseURL, "delete", "/123_1"); code != exitOK {
+		t.Fatalf("fail to delete. [stderr:%v]", stderr)
+	}
+
+	code, stdout, _ = runTest(t, "-token=token", baseURL, "get", "-all-pages", "/me/feed")
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+
+	if code != exitOK || len(lines) != 3 || lines[2] != `{"id":"3"}` {
+		t.Fatalf("invalid all pages output. [code:%v] [stdout:%v]", code, stdout)
+	}
+
+	// flags can be put after args.
+	code, stdout, stderr = runTest(t, "-token=token", "get", "/me/feed", "limit=2", "-all-pages", baseURL)
+	lines = strings.Split(strings.TrimSpace(stdout), "\n")
+
+	if code != exitOK || len(lines) != 3 {
+		t.Fatalf("flags after args must be parsed. [code:%v] [stdout:%v] [stderr:%v]", code, stdout, stderr)
+	}
+
+	server.LastRequest("GET", "/me/feed").AssertParams(t, facebook.Params{"limit": "2"})
+
+	// args after "--" are never parsed as flags.
+	code, _, stderr = runTest(t, "-token=token", baseURL, "post", "/me/feed", "--", "-debug=yes")
+
+	if code != exitOK {
+		t.Fatalf("args after -- must not be parsed as flags. [code:%v] [stderr:%v]", code, stderr)
+	}
+
+	server.LastRequest("POST", "/me/feed").AssertParams(t, facebook.Params{"-debug": "yes"})
+
+	if code, _, stderr = runTest(t, "-token=token", baseURL, "get", "/unknown"); code != exitError || !strings.Contains(stderr, "unsupported") {
+		t.Fatalf("graph api error must fail. [code:%v] [stderr:%v]", code, stderr)
+	}
+
+	if code, _, _ = runTest(t, "get", "/me", "invalid"); code != exitUsage {
+		t.Fatalf("invalid param must fail with usage. [code:%v]", code)
+	}
+
+	if code, _, _ = runTest(t, "unknown"); code != exitUsage {
+		t.Fatalf("unknown command must fail with usage. [code:%v]", code)
+	}
+}
+
+func TestBatchCommand(t *testing.T) {
+	server := fbtest.NewServer()
+	defer server.Close()
+
+	server.Respond("GET", "/me", facebook.Result{"id": "123"})
+	file := filepath.Join(t.TempDir(), "batch.json")
+	os.WriteFile(file, []byte(`[{"method":"GET","relative_url":"me"},{"method":"GET","relative_url":"unknown"}]`), 0644)
+
+	code, stdout, stderr := runTest(t, "-token=token", "-base-url="+server.URL+"/", "batch", file)
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+
+	if code != exitOK || len(lines) != 2 || lines[0] != `{"body":{"id":"123"},"code":200}` || !strings.Contains(lines[1], `"code":400`) {
+		t.Fatalf("invalid batch output. [code:%v] [stdout:%v] [stderr:%v]", code, stdout, stderr)
+	}
+}
+
+func TestTokenCommands(t *testing.T) {
+	server := fbtest.NewServer()
+	defer server.Close()
+
+	server.Respond("GET", "/debug_token", facebook.Result{
+		"data": facebook.Result{"app_id": "123", "is_valid": true},
+	})
+	server.Respond("POST", "/oauth/access_token", facebook.Result{
+		"access_token": "long-lived",
+		"expires_in":   5183944,
+	})
+	baseURL := "-base-url=" + server.URL + "/"
+
+	code, stdout, stderr := runTest(t, "-app-id=123", "-app-secret=secret", baseURL, "token", "inspect", "user-token")
+
+	if code != exitOK || !strings.Contains(stdout, `"is_valid": true`) {
+		t.Fatalf("invalid inspect output. [code:%v] [stdout:%v] [stderr:%v]", code, stdout, stderr)
+	}
+
+	server.LastRequest("GET", "/debug_token").AssertParams(t, facebook.Params{"input_token": "user-token"})
+
+	code, stdout, stderr = runTest(t, "-app-id=123", "-app-secret=secret", baseURL, "token", "exchange", "short-lived")
+
+	if code != exitOK || !strings.Contains(stdout, `"access_token": "long-lived"`) {
+		t.Fatalf("invalid exchange output. [code:%v] [stdout:%v] [stderr:%v]", code, stdout, stderr)
+	}
+
+	if code, _, _ = runTest(t, "token", "inspect", "user-token"); code != exitUsage {
+		t.Fatalf("app is required. [code:%v]", code)
+	}
+}
+
+func TestSignedRequestCommand(t *testing.T) {
+	app := facebook.New("123", "secret")
+	signedRequest, err := app.MakeSignedRequest(facebook.Result{"user_id": "456"})
+
+	if err != nil {
+		t.Fatalf("fail to make signed request. [e:%v]", err)
+	}
+
+	code, stdout, stderr := runTest(t, "-app-id=123", "-app-secret=secret", "signed-request", "decode", signedRequest)
+
+	if code != exitOK || !strings.Contains(stdout, `"user_id": "456"`) {
+		t.Fatalf("invalid decode output. [code:%v] [stdout:%v] [stderr:%v]", code, stdout, stderr)
+	}
+
+	if code, _, _ = runTest(t, "-app-id=123", "-app-secret=other", "signed-request", "decode", signedRequest); code != exitError {
+		t.Fatalf("bad signature must fail. [code:%v]", code)
+	}
+}
@@ -0,0 +1,148 @@
+// A facebook graph api client in go.
+// https://github.com/huandu/facebook/
+//
+// Copyright 2012, Huan Du
+// Licensed under the MIT license
+// https://github.com/huandu/facebook/blob/master/LICENSE
+
+package facebook
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+var errEmptyDeletionSecret = errors.New("facebook: cannot verify callback signed request without app secret")
+
+// DeletionRequest is the signed request posted to the Deauthorize callback URL
+// or the Data Deletion callback URL of an app.
+//
+// Facebook document: https://developers.facebook.com/docs/development/create-an-app/app-dashboard/data-deletion-callback
+type DeletionRequest struct {
+	UserID    string    // app-scoped id of the user.
+	Algorithm string    // signature algorithm. It's always "HMAC-SHA256".
+	IssuedAt  time.Time // the time when the request is issued.
+	Payload   Result    // the full signed request payload.
+}
+
+// DeletionResponse is the response required by the Data Deletion callback.
+type DeletionResponse struct {
+	URL              string `json:"url"`               // URL to check the deletion status.
+	ConfirmationCode string `json:"confirmation_code"` // code to identify the deletion request.
+}
+
+// DataDeletionFunc starts deleting user data and returns the URL to check deletion status and
+// the confirmation code of the deletion.
+type DataDeletionFunc func(req *DeletionRequest) (statusURL, confirmationCode string, err error)
+
+// DeauthorizeFunc is called when a user removes the app.
+type DeauthorizeFunc func(req *DeletionRequest) error
+
+// ParseDeletionRequest verifies the signed_request in a Deauthorize or Data Deletion
+// callback request and returns the typed request.
+//
+// Returns error if app secret is empty, as anyone can sign a request without secret.
+func (app *App) ParseDeletionRequest(r *http.Request) (*DeletionRequest, error) {
+	if app.AppSecret == "" {
+		return nil, errEmptyDeletionSecret
+	}
+
+	if err := r.ParseForm(); err != nil {
+		return nil, fmt.Errorf("facebook: cannot parse callback request form; %w", err)
+	}
+
+	signedRequest := r.PostForm.Get("signed_request")
+
+	if signedRequest == "" {
+		return nil, fmt.Errorf("facebook: signed_request is missing in callback request")
+	}
+
+	sr, err := app.DecodeSignedRequest(signedRequest)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if sr.UserID == "" {
+		return nil, fmt.Errorf("facebook: user_id is missing in signed request")
+	}
+
+	req := &DeletionRequest{
+		UserID:    sr.UserID,
+		Algorithm: sr.Algorithm,
+		IssuedAt:  sr.IssuedAt,
+		Payload:   sr.Payload,
+	}
+
+	return req, nil
+}
+
+// DataDeletionHandler returns an http.Handler for the Data Deletion callback URL.
+// The handler verifies the request, calls fn and responds with the JSON body
+// containing the status URL and confirmation code required by facebook.
+func (app *App) DataDeletionHandler(fn DataDeletionFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req, ok := app.serveDeletionRequest(w, r)
+
+		if !ok {
+			return
+		}
+
+		statusURL, code, err := fn(req)
+
+		if err != nil {
+			http.Error(w, "fail to delete data", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(&DeletionResponse{
+			URL:              statusURL,
+			ConfirmationCode: code,
+		})
+	})
+}
+
+// DeauthorizeHandler returns an http.Handler for the Deauthorize callback URL.
+// The handler verifies the request and calls fn.
+func (app *App) DeauthorizeHandler(fn DeauthorizeFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req, ok := app.serveDeletionRequest(w, r)
+
+		if !ok {
+			return
+		}
+
+		if err := fn(req); err != nil {
+			http.Error(w, "fail to deauthorize", http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+func (app *App) serveDeletionRequest(w http.ResponseWriter, r *http.Request) (*DeletionRequest, bool) {
+	if r.Method != "POST" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return nil, false
+	}
+
+	req, err := app.ParseDeletionRequest(r)
+
+	// it's a server misconfiguration rather than a bad request.
+	if errors.Is(err, errEmptyDeletionSecret) {
+		http.Error(w, "app secret is not configured", http.StatusInternalServerError)
+		return nil, false
+	}
+
+	if err != nil {
+		http.Error(w, "invalid signed request", http.StatusBadRequest)
+		return nil, false
+	}
+
+	return req, true
+}
@@ -0,0 +1,124 @@
+// A facebook graph api client in go.
+// https://github.com/huandu/facebook/
+//
+// Copyright 2012, Huan Du
+// Licensed under the MIT license
+// https://github.com/huandu/facebook/blob/master/LICENSE
+
+package facebook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testSignRequest(secret, payload string) string {
+	encoded := base64.RawURLEncoding.EncodeToString([]byte(payload))
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)) + "." + encoded
+}
+
+func newTestDeletionRequest(signedRequest string) *http.Request {
+	form := url.Values{"signed_request": {signedRequest}}
+	r := httptest.NewRequest("POST", "/callback", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return r
+}
+
+func TestDataDeletionHandler(t *testing.T) {
+	app := New("123", "secret")
+	signedRequest := testSignRequest("secret", `{"algorithm":"HMAC-SHA256","issued_at":1600000000,"user_id":"456"}`)
+
+	var received *DeletionRequest
+	handler := app.DataDeletionHandler(func(req *DeletionRequest) (string, string, error) {
+		received = req
+		return "https://example.com/deletion?id=abc", "abc", nil
+	})
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newTestDeletionRequest(signedRequest))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("deletion request must be accepted. [code:%v] [body:%v]", w.Code, w.Body.String())
+	}
+
+	if received.UserID != "456" || received.Algorithm != "HMAC-SHA256" || received.IssuedAt.Unix() != 1600000000 {
+		t.Fatalf("invalid deletion request. [req:%#v]", received)
+	}
+
+	var resp DeletionResponse
+
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp.URL != "https://example.com/deletion?id=abc" || resp.ConfirmationCode != "abc" {
+		t.Fatalf("invalid deletion response. [body:%v] [e:%v]", w.Body.String(), err)
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, newTestDeletionRequest(testSignRequest("wrong", `{"algorithm":"HMAC-SHA256","user_id":"456"}`)))
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("request with bad signature must be rejected. [code:%v]", w.Code)
+	}
+}
+
+func TestDeauthorizeHandler(t *testing.T) {
+	app := New("123", "secret")
+	var userID string
+	handler := app.DeauthorizeHandler(func(req *DeletionRequest) error {
+		userID = req.UserID
+		return nil
+	})
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newTestDeletionRequest(testSignRequest("secret", `{"algorithm":"HMAC-SHA256","issued_at":1600000000,"user_id":"456"}`)))
+
+	if w.Code != http.StatusOK || userID != "456" {
+		t.Fatalf("deauthorize request must be accepted. [code:%v] [user_id:%v]", w.Code, userID)
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/callback", nil))
+
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("only POST is allowed. [code:%v]", w.Code)
+	}
+}
+
+func TestDeletionHandlerWithoutAppSecret(t *testing.T) {
+	app := New("123", "")
+	signedRequest := testSignRequest("", fmt.Sprintf(`{"algorithm":"HMAC-SHA256","issued_at":%v,"user_id":"42"}`, time.Now().Unix()))
+
+	if _, err := app.ParseDeletionRequest(newTestDeletionRequest(signedRequest)); err == nil {
+		t.Fatalf("signed request must not be verified without app secret.")
+	}
+
+	called := false
+	handlers := []http.Handler{
+		app.DataDeletionHandler(func(req *DeletionRequest) (string, string, error) {
+			called = true
+			return "", "", nil
+		}),
+		app.DeauthorizeHandler(func(req *DeletionRequest) error {
+			called = true
+			return nil
+		}),
+	}
+
+	for _, handler := range handlers {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, newTestDeletionRequest(signedRequest))
+
+		if w.Code != http.StatusInternalServerError || called {
+			t.Fatalf("request must be rejected without app secret. [code:%v] [called:%v]", w.Code, called)
+		}
+	}
+}
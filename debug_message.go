@@ -0,0 +1,108 @@
+// A facebook graph api client in go.
+// https://github.com/huandu/facebook/
+//
+// Copyright 2012, Huan Du
+// Licensed under the MIT license
+// https://github.com/huandu/facebook/blob/master/LICENSE
+
+package facebook
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultDebugMessageWindow is the default window to deduplicate debug messages.
+const DefaultDebugMessageWindow = time.Hour
+
+// DebugMessageCallback is called with a debug message in graph api response
+// and the path of the request, e.g. "/me/feed".
+type DebugMessageCallback func(session *Session, path string, msg DebugMessage)
+
+type debugMessageWatcher struct {
+	mu       sync.Mutex
+	callback DebugMessageCallback
+	window   time.Duration
+	seen     map[DebugMessage]time.Time
+}
+
+// OnDebugMessage sets a callback which is called with every debug message in responses.
+// Set callback to nil to remove it.
+//
+// Facebook sends debug messages only when debug mode is on, e.g. SetDebug(DEBUG_ALL).
+//
+// The same message, which has the same type, message and link, is reported at most
+// once in window no matter which path it comes from. DefaultDebugMessageWindow is
+// used if window is 0. Messages are not deduplicated if window is negative.
+func (session *Session) OnDebugMessage(callback DebugMessageCallback, window time.Duration) {
+	if callback == nil {
+		session.debugMessageWatcher = nil
+		return
+	}
+
+	if window == 0 {
+		window = DefaultDebugMessageWindow
+	}
+
+	session.debugMessageWatcher = &debugMessageWatcher{
+		callback: callback,
+		window:   window,
+		seen:     map[DebugMessage]time.Time{},
+	}
+}
+
+// reportDebugMessages calls debug message callback with messages in res.
+func (session *Session) reportDebugMessages(path string, res Result) {
+	watcher := session.debugMessageWatcher
+
+	if watcher == nil {
+		return
+	}
+
+	debugInfo := res.DebugInfo()
+
+	if debugInfo == nil || len(debugInfo.Messages) == 0 {
+		return
+	}
+
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path = path[:i]
+	}
+
+	path = "/" + strings.Join(splitPath(path), "/")
+
+	for _, msg := range watcher.filter(debugInfo.Messages) {
+		watcher.callback(session, path, msg)
+	}
+}
+
+// filter returns messages which are not reported in window.
+func (watcher *debugMessageWatcher) filter(messages []DebugMessage) []DebugMessage {
+	if watcher.window < 0 {
+		return messages
+	}
+
+	watcher.mu.Lock()
+	defer watcher.mu.Unlock()
+
+	now := time.Now()
+	var filtered []DebugMessage
+
+	for msg, seenAt := range watcher.seen {
+		if now.Sub(seenAt) >= watcher.window {
+			delete(watcher.seen, msg)
+		}
+	}
+
+	for _, msg := range messages {
+		if _, ok := watcher.seen[msg]; ok {
+			continue
+		}
+
+		watcher.seen[msg] = now
+		filtered = append(filtered, msg)
+	}
+
+	return filtered
+}
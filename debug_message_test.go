@@ -0,0 +1,87 @@
+// A facebook graph api client in go.
+// https://github.com/huandu/facebook/
+//
+// Copyright 2012, Huan Du
+// Licensed under the MIT license
+// https://github.com/huandu/facebook/blob/master/LICENSE
+
+package facebook
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSessionOnDebugMessage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":"123","__debug__":{"messages":[
+			{"type":"warning","message":"The 'foo' field is deprecated","link":"https://developers.facebook.com/docs/"},
+			{"type":"info","message":"No permission"}
+		]}}`)
+	}))
+	defer server.Close()
+
+	type received struct {
+		path string
+		msg  DebugMessage
+	}
+
+	var messages []received
+	session := &Session{
+		BaseURL: server.URL + "/",
+		Version: "v19.0",
+	}
+	session.SetDebug(DEBUG_ALL)
+	session.OnDebugMessage(func(s *Session, path string, msg DebugMessage) {
+		messages = append(messages, received{path, msg})
+	}, 0)
+
+	session.Get("/me?fields=id", nil)
+	session.Get("/123/feed", nil)
+
+	if len(messages) != 2 {
+		t.Fatalf("messages must be deduplicated. [messages:%v]", messages)
+	}
+
+	if m := messages[0]; m.path != "/me" || m.msg.Type != "warning" || m.msg.Link != "https://developers.facebook.com/docs/" {
+		t.Fatalf("invalid message. [message:%#v]", m)
+	}
+
+	if m := messages[1]; m.path != "/me" || m.msg.Message != "No permission" {
+		t.Fatalf("invalid message. [message:%#v]", m)
+	}
+
+	request, _ := http.NewRequest("GET", server.URL+"/v19.0/456", nil)
+	messages = nil
+	session.OnDebugMessage(func(s *Session, path string, msg DebugMessage) {
+		messages = append(messages, received{path, msg})
+	}, time.Nanosecond)
+	session.Get("/me", nil)
+	time.Sleep(time.Millisecond)
+	session.Request(request)
+
+	if len(messages) != 4 || messages[2].path != "/456" {
+		t.Fatalf("messages must be reported again after window. [messages:%v]", messages)
+	}
+
+	messages = nil
+	session.OnDebugMessage(func(s *Session, path string, msg DebugMessage) {
+		messages = append(messages, received{path, msg})
+	}, -1)
+	session.Get("/me", nil)
+	session.Get("/me", nil)
+
+	if len(messages) != 4 {
+		t.Fatalf("messages must not be deduplicated with negative window. [messages:%v]", messages)
+	}
+
+	session.OnDebugMessage(nil, 0)
+	session.Get("/me", nil)
+
+	if len(messages) != 4 {
+		t.Fatalf("callback must be removed.")
+	}
+}
@@ -0,0 +1,172 @@
+// A facebook graph api client in go.
+// https://github.com/huandu/facebook/
+//
+// Copyright 2012, Huan Du
+// Licensed under the MIT license
+// https://github.com/huandu/facebook/blob/master/LICENSE
+
+package facebook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Facebook graph api error subcodes in device login.
+const (
+	ErrSubcodeDeviceLoginPending  = 1349174 // user hasn't authorized the device yet.
+	ErrSubcodeDeviceLoginSlowDown = 1349172 // device polls too frequently.
+	ErrSubcodeDeviceLoginExpired  = 1349152 // device code has expired.
+)
+
+// defaultDeviceLoginInterval is used if facebook doesn't return polling interval.
+const defaultDeviceLoginInterval = 5 * time.Second
+
+// deviceLoginSlowDownDelta is added to polling interval when facebook asks to slow down.
+const deviceLoginSlowDownDelta = 5 * time.Second
+
+// ErrDeviceLoginExpired is returned by DeviceLogin#Poll when device code expires
+// before user authorizes the device.
+var ErrDeviceLoginExpired = errors.New("facebook: device login code has expired")
+
+// DeviceLogin is a device login started by App#StartDeviceLogin.
+//
+// Show VerificationURI and UserCode to user and call Poll to wait for user authorization.
+//
+// Facebook document: https://developers.facebook.com/docs/facebook-login/for-devices
+type DeviceLogin struct {
+	Code            string        // device code used to poll login status.
+	UserCode        string        // code which user enters in VerificationURI.
+	VerificationURI string        // url which user visits to authorize the device.
+	ExpiresAt       time.Time     // time when Code and UserCode expire.
+	Interval        time.Duration // min interval between two polling requests.
+
+	app           *App
+	slowDownDelta time.Duration // added to Interval on slow down. deviceLoginSlowDownDelta is used if it's 0.
+}
+
+// StartDeviceLogin starts a device login with scopes.
+// App.ClientToken is required to call device login api.
+func (app *App) StartDeviceLogin(scopes []string) (*DeviceLogin, error) {
+	if app.ClientToken == "" {
+		return nil, fmt.Errorf("facebook: client token is required to start device login")
+	}
+
+	params := Params{
+		"access_token": app.clientAccessToken(),
+	}
+
+	if len(scopes) != 0 {
+		params["scope"] = strings.Join(scopes, ",")
+	}
+
+	res, err := app.getSession().sendOauthRequest("/device/login", params)
+
+	if err != nil {
+		return nil, fmt.Errorf("facebook: fail to start device login with error %w", err)
+	}
+
+	var data struct {
+		Code            string
+		UserCode        string `facebook:"user_code"`
+		VerificationURI string `facebook:"verification_uri"`
+		ExpiresIn       Int64  `facebook:"expires_in"`
+		Interval        Int64
+	}
+
+	if err = res.Decode(&data); err != nil {
+		return nil, err
+	}
+
+	if data.Code == "" {
+		return nil, fmt.Errorf("facebook: device code is missing in device login response")
+	}
+
+	dl := &DeviceLogin{
+		Code:            data.Code,
+		UserCode:        data.UserCode,
+		VerificationURI: data.VerificationURI,
+		Interval:        time.Duration(data.Interval) * time.Second,
+		app:             app,
+	}
+
+	if data.ExpiresIn > 0 {
+		dl.ExpiresAt = time.Now().Add(time.Duration(data.ExpiresIn) * time.Second)
+	}
+
+	if dl.Interval <= 0 {
+		dl.Interval = defaultDeviceLoginInterval
+	}
+
+	return dl, nil
+}
+
+// Poll polls login status every Interval until user authorizes the device,
+// device code expires or ctx is done.
+//
+// Returns a session with user access token if user authorizes the device.
+// Returns ErrDeviceLoginExpired if device code expires.
+func (dl *DeviceLogin) Poll(ctx context.Context) (*Session, error) {
+	session := dl.app.getSession().WithContext(ctx)
+	timer := time.NewTimer(dl.Interval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+
+		if !dl.ExpiresAt.IsZero() && time.Now().After(dl.ExpiresAt) {
+			return nil, ErrDeviceLoginExpired
+		}
+
+		res, err := session.sendOauthRequest("/device/login_status", Params{
+			"access_token": dl.app.clientAccessToken(),
+			"code":         dl.Code,
+		})
+
+		if err == nil {
+			token, err := newTokenFromResult(res)
+
+			if err != nil {
+				return nil, err
+			}
+
+			return dl.app.SessionFromToken(token), nil
+		}
+
+		e, ok := err.(*Error)
+
+		if !ok {
+			return nil, err
+		}
+
+		switch e.ErrorSubcode {
+		case ErrSubcodeDeviceLoginPending:
+		case ErrSubcodeDeviceLoginSlowDown:
+			delta := dl.slowDownDelta
+
+			if delta == 0 {
+				delta = deviceLoginSlowDownDelta
+			}
+
+			dl.Interval += delta
+		case ErrSubcodeDeviceLoginExpired:
+			return nil, ErrDeviceLoginExpired
+		default:
+			return nil, err
+		}
+
+		timer.Reset(dl.Interval)
+	}
+}
+
+// clientAccessToken returns the client access token in the form of "app_id|client_token".
+func (app *App) clientAccessToken() string {
+	return app.AppId + "|" + app.ClientToken
+}
@@ -0,0 +1,137 @@
+// A facebook graph api client in go.
+// https://github.com/huandu/facebook/
+//
+// Copyright 2012, Huan Du
+// Licensed under the MIT license
+// https://github.com/huandu/facebook/blob/master/LICENSE
+
+package facebook
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newTestDeviceLoginApp(handler http.HandlerFunc) (*App, *httptest.Server) {
+	server := httptest.NewServer(handler)
+	session := &Session{
+		BaseURL: server.URL + "/",
+		Version: "v19.0",
+	}
+	app := New("123", "secret")
+	app.ClientToken = "client"
+	app.SetSession(session)
+	return app, server
+}
+
+func TestDeviceLogin(t *testing.T) {
+	polls := 0
+	app, server := newTestDeviceLoginApp(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+
+		if r.Form.Get("access_token") != "123|client" {
+			t.Errorf("invalid client access token. [token:%v]", r.Form.Get("access_token"))
+		}
+
+		switch r.URL.Path {
+		case "/v19.0/device/login":
+			if r.Form.Get("scope") != "public_profile,email" {
+				t.Errorf("invalid scope. [scope:%v]", r.Form.Get("scope"))
+			}
+
+			fmt.Fprint(w, `{"code":"device-code","user_code":"ABCD","verification_uri":"https://www.facebook.com/device","expires_in":420,"interval":5}`)
+
+		case "/v19.0/device/login_status":
+			if r.Form.Get("code") != "device-code" {
+				t.Errorf("invalid device code. [code:%v]", r.Form.Get("code"))
+			}
+
+			polls++
+
+			switch polls {
+			case 1:
+				fmt.Fprint(w, `{"error":{"message":"pending","code":31,"error_subcode":1349174}}`)
+			case 2:
+				fmt.Fprint(w, `{"error":{"message":"slow down","code":17,"error_subcode":1349172}}`)
+			default:
+				fmt.Fprint(w, `{"access_token":"user-token","expires_in":5183944}`)
+			}
+
+		default:
+			t.Errorf("unexpected path. [path:%v]", r.URL.Path)
+		}
+	})
+	defer server.Close()
+
+	dl, err := app.StartDeviceLogin([]string{"public_profile", "email"})
+
+	if err != nil {
+		t.Fatalf("fail to start device login. [e:%v]", err)
+	}
+
+	if dl.UserCode != "ABCD" || dl.VerificationURI != "https://www.facebook.com/device" || dl.Interval != 5*time.Second {
+		t.Fatalf("invalid device login. [dl:%#v]", dl)
+	}
+
+	if time.Until(dl.ExpiresAt) <= 400*time.Second {
+		t.Fatalf("invalid expire time. [expires_at:%v]", dl.ExpiresAt)
+	}
+
+	dl.Interval = time.Millisecond
+	dl.slowDownDelta = time.Millisecond
+	session, err := dl.Poll(context.Background())
+
+	if err != nil {
+		t.Fatalf("fail to poll device login. [e:%v]", err)
+	}
+
+	if session.AccessToken() != "user-token" || session.Token().ExpiresAt.IsZero() {
+		t.Fatalf("invalid session token. [token:%v]", session.Token())
+	}
+
+	if polls != 3 || dl.Interval != 2*time.Millisecond {
+		t.Fatalf("invalid polling. [polls:%v] [interval:%v]", polls, dl.Interval)
+	}
+}
+
+func TestDeviceLoginExpired(t *testing.T) {
+	app, server := newTestDeviceLoginApp(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"error":{"message":"expired","code":31,"error_subcode":1349152}}`)
+	})
+	defer server.Close()
+
+	dl := &DeviceLogin{
+		Code:     "device-code",
+		Interval: time.Millisecond,
+		app:      app,
+	}
+
+	if _, err := dl.Poll(context.Background()); err != ErrDeviceLoginExpired {
+		t.Fatalf("device login must expire. [e:%v]", err)
+	}
+
+	dl.ExpiresAt = time.Now().Add(-time.Second)
+
+	if _, err := dl.Poll(context.Background()); err != ErrDeviceLoginExpired {
+		t.Fatalf("device login must expire without polling. [e:%v]", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	dl.ExpiresAt = time.Time{}
+	dl.Interval = time.Hour
+
+	if _, err := dl.Poll(ctx); err != context.Canceled {
+		t.Fatalf("polling must stop when context is done. [e:%v]", err)
+	}
+
+	app.ClientToken = ""
+
+	if _, err := app.StartDeviceLogin(nil); err == nil {
+		t.Fatalf("client token is required.")
+	}
+}
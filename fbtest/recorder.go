@@ -0,0 +1,395 @@
+// A facebook graph api client in go.
+// https://github.com/huandu/facebook/
+//
+// Copyright 2012, Huan Du
+// Licensed under the MIT license
+// https://github.com/huandu/facebook/blob/master/LICENSE
+
+package fbtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/huandu/facebook/v2"
+)
+
+// RecorderMode is the mode of a Recorder.
+type RecorderMode int
+
+// Recorder modes.
+const (
+	ModeAuto   RecorderMode = iota // record if cassette file doesn't exist. Otherwise, replay.
+	ModeRecord                     // send requests to facebook and record them.
+	ModeReplay                     // replay recorded interactions and never send requests.
+)
+
+// Scrubbed is the value replacing secrets in cassettes.
+const Scrubbed = "[SCRUBBED]"
+
+// ScrubbedParams are params scrubbed in recorded requests.
+// They are also scrubbed in json fields and urls, e.g. paging urls, of recorded responses.
+var ScrubbedParams = []string{"access_token", "appsecret_proof", "input_token", "client_secret", "fb_exchange_token"}
+
+// ScrubbedHeaders are response headers whose values are always scrubbed.
+var ScrubbedHeaders = []string{"Set-Cookie", "Authorization"}
+
+// Interaction is a recorded request and its response.
+type Interaction struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`    // request url without query string.
+	Params string      `json:"params"` // scrubbed query and form params sorted by key.
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body"`
+}
+
+// Recorder is a facebook.HttpClient recording real Graph API interactions
+// to a cassette file and replaying them offline.
+//
+// Requests are matched by method, url and scrubbed params sorted by key.
+// Interactions with the same request are replayed in recorded order and
+// the last one is repeated when all of them are used.
+//
+//	rec, err := fbtest.NewRecorder("testdata/me.json", fbtest.ModeAuto)
+//	defer rec.Save()
+//	session.HttpClient = rec
+type Recorder struct {
+	Client facebook.HttpClient // client to send real requests. http.DefaultClient is used if it's nil.
+
+	path         string
+	mode         RecorderMode
+	mu           sync.Mutex
+	interactions []*Interaction
+	used         []bool
+}
+
+var _ facebook.HttpClient = &Recorder{}
+
+// NewRecorder creates a Recorder with cassette file path.
+// The cassette is loaded if Recorder replays interactions.
+func NewRecorder(path string, mode RecorderMode) (*Recorder, error) {
+	r := &Recorder{
+		path: path,
+		mode: mode,
+	}
+
+	if mode == ModeAuto {
+		if _, err := os.Stat(path); err == nil {
+			r.mode = ModeReplay
+		} else if os.IsNotExist(err) {
+			r.mode = ModeRecord
+		} else {
+			return nil, err
+		}
+	}
+
+	if r.mode != ModeReplay {
+		return r, nil
+	}
+
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, fmt.Errorf("fbtest: cannot read cassette; %w", err)
+	}
+
+	if err = json.Unmarshal(data, &r.interactions); err != nil {
+		return nil, fmt.Errorf("fbtest: cannot parse cassette %v; %w", path, err)
+	}
+
+	r.used = make([]bool, len(r.interactions))
+	return r, nil
+}
+
+// Recording reports whether Recorder sends real requests.
+func (r *Recorder) Recording() bool {
+	return r.mode == ModeRecord
+}
+
+// Save writes recorded interactions to the cassette file.
+// It does nothing when Recorder replays interactions.
+func (r *Recorder) Save() error {
+	if !r.Recording() {
+		return nil
+	}
+
+	r.mu.Lock()
+	data, err := json.MarshalIndent(r.interactions, "", "  ")
+	r.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(r.path, append(data, '\n'), 0644)
+}
+
+// Do records or replays a request.
+func (r *Recorder) Do(req *http.Request) (*http.Response, error) {
+	params, err := requestParams(req)
+
+	if err != nil {
+		return nil, fmt.Errorf("fbtest: cannot read request params; %w", err)
+	}
+
+	u := *req.URL
+	u.RawQuery = ""
+	u.Fragment = ""
+	key := &Interaction{
+		Method: req.Method,
+		URL:    u.String(),
+		Params: params,
+	}
+
+	if r.Recording() {
+		return r.record(req, key)
+	}
+
+	return r.replay(req, key)
+}
+
+// Get sends a GET request through Do.
+func (r *Recorder) Get(url string) (*http.Response, error) {
+	req, err := http.NewRequest("GET", url, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return r.Do(req)
+}
+
+// Post sends a POST request through Do.
+func (r *Recorder) Post(url string, bodyType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequest("POST", url, body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", bodyType)
+	return r.Do(req)
+}
+
+func (r *Recorder) record(req *http.Request, interaction *Interaction) (*http.Response, error) {
+	client := r.Client
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	interaction.Status = resp.StatusCode
+	interaction.Header = scrubHeader(resp.Header)
+	interaction.Body = scrubSecrets(string(body))
+
+	r.mu.Lock()
+	r.interactions = append(r.interactions, interaction)
+	r.mu.Unlock()
+
+	return resp, nil
+}
+
+func (r *Recorder) replay(req *http.Request, key *Interaction) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	last := -1
+
+	for i, interaction := range r.interactions {
+		if interaction.Method != key.Method || interaction.URL != key.URL || interaction.Params != key.Params {
+			continue
+		}
+
+		last = i
+
+		if !r.used[i] {
+			break
+		}
+	}
+
+	if last < 0 {
+		return nil, fmt.Errorf("fbtest: no recorded interaction for %v %v?%v", key.Method, key.URL, key.Params)
+	}
+
+	r.used[last] = true
+	interaction := r.interactions[last]
+	header := interaction.Header.Clone()
+
+	if header == nil {
+		header = http.Header{}
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", interaction.Status, http.StatusText(interaction.Status)),
+		StatusCode:    interaction.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(interaction.Body)),
+		ContentLength: int64(len(interaction.Body)),
+		Request:       req,
+	}, nil
+}
+
+// scrubSecrets replaces values of ScrubbedParams in json fields and urls with Scrubbed.
+func scrubSecrets(s string) string {
+	names := make([]string, 0, len(ScrubbedParams))
+
+	for _, name := range ScrubbedParams {
+		names = append(names, regexp.QuoteMeta(name))
+	}
+
+	if len(names) == 0 {
+		return s
+	}
+
+	pattern := strings.Join(names, "|")
+	jsonField := regexp.MustCompile(`"(` + pattern + `)"\s*:\s*"[^"]*"`)
+	// "&" is escaped as "\u0026" in json strings.
+	queryParam := regexp.MustCompile(`(^|[^A-Za-z0-9_]|\\u0026)(` + pattern + `)=[^&"'\s;\\]*`)
+
+	s = jsonField.ReplaceAllString(s, `"$1":"`+Scrubbed+`"`)
+	return queryParam.ReplaceAllString(s, "${1}${2}="+Scrubbed)
+}
+
+// scrubHeader returns a copy of header with secrets scrubbed.
+func scrubHeader(header http.Header) http.Header {
+	header = header.Clone()
+
+	for k, values := range header {
+		for i, v := range values {
+			values[i] = scrubSecrets(v)
+		}
+
+		header[k] = values
+	}
+
+	for _, k := range ScrubbedHeaders {
+		if values := header.Values(k); len(values) != 0 {
+			header.Set(k, Scrubbed)
+		}
+	}
+
+	return header
+}
+
+// requestParams returns scrubbed query and form params sorted by key.
+// The request body is restored so that it can be sent again.
+func requestParams(req *http.Request) (string, error) {
+	values := url.Values{}
+
+	for k, v := range req.URL.Query() {
+		values[k] = append(values[k], v...)
+	}
+
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+
+		if err != nil {
+			return "", err
+		}
+
+		req.Body = io.NopCloser(bytes.NewReader(body))
+
+		if err = parseBody(req.Header.Get("Content-Type"), body, values); err != nil {
+			return "", err
+		}
+	}
+
+	for _, k := range ScrubbedParams {
+		if _, ok := values[k]; ok {
+			values.Set(k, Scrubbed)
+		}
+	}
+
+	// secrets can be nested in other params, e.g. relative_url or body of operations in batch.
+	for _, v := range values {
+		for i := range v {
+			v[i] = scrubSecrets(v[i])
+		}
+	}
+
+	return values.Encode(), nil
+}
+
+func parseBody(contentType string, body []byte, values url.Values) error {
+	mediaType, params, _ := mime.ParseMediaType(contentType)
+
+	switch mediaType {
+	case "application/x-www-form-urlencoded":
+		form, err := url.ParseQuery(string(body))
+
+		if err != nil {
+			return err
+		}
+
+		for k, v := range form {
+			values[k] = append(values[k], v...)
+		}
+
+	case "multipart/form-data":
+		reader := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+
+		for {
+			part, err := reader.NextPart()
+
+			if err == io.EOF {
+				break
+			}
+
+			if err != nil {
+				return err
+			}
+
+			if part.FileName() != "" {
+				values.Add(part.FormName(), "@"+part.FileName())
+				continue
+			}
+
+			data, err := io.ReadAll(part)
+
+			if err != nil {
+				return err
+			}
+
+			values.Add(part.FormName(), string(data))
+		}
+
+	default:
+		// binary body, e.g. resumable upload, is matched by url and query only.
+	}
+
+	return nil
+}
@@ -0,0 +1,235 @@
+// A facebook graph api client in go.
+// https://github.com/huandu/facebook/
+//
+// Copyright 2012, Huan Du
+// Licensed under the MIT license
+// https://github.com/huandu/facebook/blob/master/LICENSE
+
+package fbtest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/huandu/facebook/v2"
+)
+
+func TestRecorder(t *testing.T) {
+	server := NewServer()
+	server.Handle("GET", "/me", func(req *Request) (facebook.Result, error) {
+		return facebook.Result{"id": "123", "fields": req.Params["fields"]}, nil
+	})
+	server.Respond("POST", "/oauth/access_token", facebook.Result{"access_token": "new-secret-token"})
+
+	cassette := filepath.Join(t.TempDir(), "testdata", "cassette.json")
+	rec, err := NewRecorder(cassette, ModeAuto)
+
+	if err != nil {
+		t.Fatalf("fail to create recorder. [e:%v]", err)
+	}
+
+	if !rec.Recording() {
+		t.Fatalf("recorder must record when cassette doesn't exist.")
+	}
+
+	session := server.Session("secret-token")
+	session.HttpClient = rec
+
+	if _, err := session.Get("/me", facebook.Params{"fields": "id", "limit": 1}); err != nil {
+		t.Fatalf("fail to get /me. [e:%v]", err)
+	}
+
+	if _, err := session.Post("/oauth/access_token", facebook.Params{"client_secret": "app-secret"}); err != nil {
+		t.Fatalf("fail to post. [e:%v]", err)
+	}
+
+	if err := rec.Save(); err != nil {
+		t.Fatalf("fail to save cassette. [e:%v]", err)
+	}
+
+	server.Close()
+	data, err := os.ReadFile(cassette)
+
+	if err != nil {
+		t.Fatalf("fail to read cassette. [e:%v]", err)
+	}
+
+	for _, secret := range []string{"secret-token", "app-secret", "new-secret-token"} {
+		if strings.Contains(string(data), secret) {
+			t.Fatalf("secret must be scrubbed in cassette. [secret:%v] [cassette:%s]", secret, data)
+		}
+	}
+
+	rec, err = NewRecorder(cassette, ModeAuto)
+
+	if err != nil {
+		t.Fatalf("fail to create recorder. [e:%v]", err)
+	}
+
+	if rec.Recording() {
+		t.Fatalf("recorder must replay when cassette exists.")
+	}
+
+	// a different token and param order must match the same interaction.
+	session.HttpClient = rec
+	session.SetAccessToken("other-token")
+
+	for i := 0; i < 2; i++ {
+		res, err := session.Get("/me", facebook.Params{"limit": 1, "fields": "id"})
+
+		if err != nil {
+			t.Fatalf("fail to replay /me. [e:%v]", err)
+		}
+
+		if res.Get("id") != "123" || res.Get("fields") != "id" {
+			t.Fatalf("invalid replayed result. [res:%v]", res)
+		}
+	}
+
+	if _, err := session.Get("/me", facebook.Params{"fields": "name"}); err == nil {
+		t.Fatalf("unrecorded request must fail.")
+	}
+}
+
+func TestRecorderPaging(t *testing.T) {
+	server := NewServer()
+	server.RespondPages("GET", "/me/feed",
+		[]interface{}{facebook.Result{"id": "1"}},
+		[]interface{}{facebook.Result{"id": "2"}},
+		[]interface{}{facebook.Result{"id": "3"}},
+	)
+
+	cassette := filepath.Join(t.TempDir(), "paging.json")
+	rec, _ := NewRecorder(cassette, ModeRecord)
+	app := facebook.New("123", "app-secret")
+	session := app.Session("SECRET_TOKEN_VALUE")
+	session.BaseURL = server.URL + "/"
+	session.HttpClient = rec
+	session.EnableAppsecretProof(true)
+	proof := session.AppsecretProof()
+
+	readAll := func() []string {
+		t.Helper()
+
+		res, err := session.Get("/me/feed", nil)
+
+		if err != nil {
+			t.Fatalf("fail to get feed. [e:%v]", err)
+		}
+
+		pr, err := res.Paging(session)
+
+		if err != nil {
+			t.Fatalf("fail to create paging result. [e:%v]", err)
+		}
+
+		var ids []string
+
+		for {
+			for _, item := range pr.Data() {
+				ids = append(ids, item.Get("id").(string))
+			}
+
+			noMore, err := pr.Next()
+
+			if err != nil {
+				t.Fatalf("fail to read next page. [e:%v]", err)
+			}
+
+			if noMore {
+				break
+			}
+		}
+
+		return ids
+	}
+
+	if ids := readAll(); strings.Join(ids, ",") != "1,2,3" {
+		t.Fatalf("invalid recorded pages. [ids:%v]", ids)
+	}
+
+	rec.Save()
+	server.Close()
+	data, _ := os.ReadFile(cassette)
+
+	for _, secret := range []string{"SECRET_TOKEN_VALUE", proof} {
+		if strings.Contains(string(data), secret) {
+			t.Fatalf("secret must be scrubbed in paging urls. [secret:%v] [cassette:%s]", secret, data)
+		}
+	}
+
+	rec, _ = NewRecorder(cassette, ModeReplay)
+	session.HttpClient = rec
+
+	if ids := readAll(); strings.Join(ids, ",") != "1,2,3" {
+		t.Fatalf("invalid replayed pages. [ids:%v]", ids)
+	}
+}
+
+func TestRecorderScrubHeader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", "https://example.com/cb?access_token=SECRET_TOKEN_VALUE&state=1")
+		w.Header().Set("Set-Cookie", "session=SECRET_COOKIE")
+		w.Write([]byte(`{"id":"123"}`))
+	}))
+	defer server.Close()
+
+	rec, _ := NewRecorder(filepath.Join(t.TempDir(), "header.json"), ModeRecord)
+
+	if _, err := rec.Get(server.URL + "/me"); err != nil {
+		t.Fatalf("fail to get. [e:%v]", err)
+	}
+
+	header := rec.interactions[0].Header
+
+	if header.Get("Location") != "https://example.com/cb?access_token="+Scrubbed+"&state=1" || header.Get("Set-Cookie") != Scrubbed {
+		t.Fatalf("secrets must be scrubbed in headers. [header:%v]", header)
+	}
+}
+
+func TestRecorderScrubBatch(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	server.Respond("GET", "/me", facebook.Result{"id": "123"})
+	server.Respond("POST", "/me/feed", facebook.Result{"id": "123_1"})
+
+	cassette := filepath.Join(t.TempDir(), "batch.json")
+	rec, _ := NewRecorder(cassette, ModeRecord)
+	session := server.Session("token")
+	session.HttpClient = rec
+
+	_, err := session.BatchApi(
+		facebook.Params{"method": facebook.GET, "relative_url": "me?access_token=SECRETTOKEN"},
+		facebook.Params{"method": facebook.POST, "relative_url": "me/feed", "body": "message=hi&access_token=SECRETBODY"},
+	)
+
+	if err != nil {
+		t.Fatalf("fail to send batch. [e:%v]", err)
+	}
+
+	rec.Save()
+	data, _ := os.ReadFile(cassette)
+
+	for _, secret := range []string{"SECRETTOKEN", "SECRETBODY"} {
+		if strings.Contains(string(data), secret) {
+			t.Fatalf("secret in batch must be scrubbed. [secret:%v] [cassette:%s]", secret, data)
+		}
+	}
+
+	// scrubbed batch must be replayed.
+	rec, _ = NewRecorder(cassette, ModeReplay)
+	session.HttpClient = rec
+	_, err = session.BatchApi(
+		facebook.Params{"method": facebook.GET, "relative_url": "me?access_token=OTHERTOKEN"},
+		facebook.Params{"method": facebook.POST, "relative_url": "me/feed", "body": "message=hi&access_token=OTHERBODY"},
+	)
+
+	if err != nil {
+		t.Fatalf("fail to replay batch. [e:%v]", err)
+	}
+}
@@ -0,0 +1,534 @@
+// A facebook graph api client in go.
+// https://github.com/huandu/facebook/
+//
+// Copyright 2012, Huan Du
+// Licensed under the MIT license
+// https://github.com/huandu/facebook/blob/master/LICENSE
+
+// Package fbtest provides an in-memory fake Graph API server for tests.
+//
+// A Server is an httptest.Server serving registered routes. Use Server#Session
+// or Server#App to create a facebook.Session or facebook.App talking to it.
+//
+//	server := fbtest.NewServer()
+//	defer server.Close()
+//
+//	server.Respond("GET", "/me", facebook.Result{"id": "123", "name": "Jane"})
+//	session := server.Session("token")
+//	res, err := session.Get("/me", facebook.Params{"fields": "id,name"})
+//
+//	server.LastRequest("GET", "/me").AssertParams(t, facebook.Params{"fields": "id,name"})
+package fbtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/huandu/facebook/v2"
+)
+
+// Usage HTTP headers.
+const (
+	appUsageHeader             = "X-App-Usage"
+	pageUsageHeader            = "X-Page-Usage"
+	adAccountUsageHeader       = "X-Ad-Account-Usage"
+	businessUseCaseUsageHeader = "X-Business-Use-Case-Usage"
+	adsInsightsThrottleHeader  = "X-Fb-Ads-Insights-Throttle"
+)
+
+// matches the version prefix in path, e.g. "/v19.0/".
+var regexpVersionPrefix = regexp.MustCompile(`^v\d+\.\d+(/|$)`)
+
+// HandlerFunc handles a Graph API request.
+// If it returns a *facebook.Error, the error is sent to client as a Graph API error.
+// Other errors are sent as an unknown error with status 500.
+type HandlerFunc func(req *Request) (facebook.Result, error)
+
+// Request is a Graph API request received by Server.
+type Request struct {
+	Method      string          // http method. A POST with "method" param uses the param value.
+	Path        string          // request path without version prefix, e.g. "/me".
+	Params      facebook.Params // query and form params. All values are strings.
+	AccessToken string          // access token in params or Authorization header.
+	Header      http.Header     // http header. It's nil for batch operations.
+	Batch       bool            // whether it's an operation in a batch request.
+}
+
+// Route is a registered route in Server.
+type Route struct {
+	Method string
+	Path   string
+
+	handler HandlerFunc
+	mu      sync.Mutex
+	calls   int
+}
+
+// Calls returns how many times the route has been called.
+func (r *Route) Calls() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.calls
+}
+
+// Server is a fake Graph API server.
+// It's safe to register routes and read requests concurrently.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.RWMutex
+	routes   map[string]*Route
+	requests []*Request
+	usage    *facebook.UsageInfo
+}
+
+// NewServer starts a new fake Graph API server. Call Close when it's not used.
+func NewServer() *Server {
+	s := &Server{
+		routes: map[string]*Route{},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Session creates a session sending all requests to the server.
+func (s *Server) Session(accessToken string) *facebook.Session {
+	session := &facebook.Session{
+		HttpClient: s.Client(),
+		BaseURL:    s.URL + "/",
+	}
+	session.SetAccessToken(accessToken)
+	return session
+}
+
+// App creates an app sending all requests, e.g. oauth requests, to the server.
+func (s *Server) App(appID, appSecret string) *facebook.App {
+	app := facebook.New(appID, appSecret)
+	app.SetSession(s.Session(""))
+	return app
+}
+
+// Handle registers a handler for method and path.
+// The path is matched without version prefix, e.g. "/me" matches "/v19.0/me".
+// A route registered later replaces the one with the same method and path.
+func (s *Server) Handle(method, path string, handler HandlerFunc) *Route {
+	route := &Route{
+		Method:  strings.ToUpper(method),
+		Path:    normalizePath(path),
+		handler: handler,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.routes[route.Method+" "+route.Path] = route
+	return route
+}
+
+// Respond registers a route always responding res.
+func (s *Server) Respond(method, path string, res facebook.Result) *Route {
+	return s.Handle(method, path, func(req *Request) (facebook.Result, error) {
+		return res, nil
+	})
+}
+
+// RespondError registers a route always responding a Graph API error.
+func (s *Server) RespondError(method, path string, err *facebook.Error) *Route {
+	return s.Handle(method, path, func(req *Request) (facebook.Result, error) {
+		return nil, err
+	})
+}
+
+// RespondPages registers a route responding data in pages.
+// Every page except the last one has a "next" url pointing back to the server.
+func (s *Server) RespondPages(method, path string, pages ...[]interface{}) *Route {
+	return s.Handle(method, path, func(req *Request) (facebook.Result, error) {
+		page := 0
+
+		if after, ok := req.Params["after"].(string); ok {
+			page, _ = strconv.Atoi(after)
+		}
+
+		data := []interface{}{}
+
+		if page >= 0 && page < len(pages) {
+			data = pages[page]
+		}
+
+		paging := facebook.Result{
+			"cursors": facebook.Result{
+				"before": strconv.Itoa(page),
+				"after":  strconv.Itoa(page + 1),
+			},
+		}
+
+		if page+1 < len(pages) {
+			query := url.Values{}
+
+			for k, v := range req.Params {
+				query.Set(k, fmt.Sprint(v))
+			}
+
+			if req.AccessToken != "" {
+				query.Set("access_token", req.AccessToken)
+			}
+
+			query.Set("after", strconv.Itoa(page+1))
+			paging["next"] = s.URL + req.Path + "?" + query.Encode()
+		}
+
+		return facebook.Result{
+			"data":   data,
+			"paging": paging,
+		}, nil
+	})
+}
+
+// SetUsage sets usage headers in all responses. Set usage to nil to remove usage headers.
+func (s *Server) SetUsage(usage *facebook.UsageInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.usage = usage
+}
+
+// Requests returns all received requests in order, including batch operations.
+func (s *Server) Requests() []*Request {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]*Request{}, s.requests...)
+}
+
+// LastRequest returns the last request received by the route of method and path.
+// It returns nil if there is no such request.
+func (s *Server) LastRequest(method, path string) *Request {
+	method = strings.ToUpper(method)
+	path = normalizePath(path)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for i := len(s.requests) - 1; i >= 0; i-- {
+		if req := s.requests[i]; req.Method == method && req.Path == path {
+			return req
+		}
+	}
+
+	return nil
+}
+
+// AssertParams checks whether every param in expected is received in the request.
+// Non-string expected values are compared in their JSON form, the same as facebook.Params encodes them.
+func (req *Request) AssertParams(t testing.TB, expected facebook.Params) {
+	t.Helper()
+
+	if req == nil {
+		t.Fatalf("fbtest: request is not received.")
+		return
+	}
+
+	for k, v := range expected {
+		actual, ok := req.Params[k]
+
+		if !ok {
+			t.Errorf("fbtest: param is missing in %v %v. [key:%v]", req.Method, req.Path, k)
+			continue
+		}
+
+		if expectedValue := paramString(v); actual != expectedValue {
+			t.Errorf("fbtest: param mismatch in %v %v. [key:%v] [expected:%v] [actual:%v]", req.Method, req.Path, k, expectedValue, actual)
+		}
+	}
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(32 << 20); err != nil && err != http.ErrNotMultipart {
+		s.writeResult(w, http.StatusBadRequest, nil, &facebook.Error{
+			Message: fmt.Sprintf("fbtest: cannot parse request; %v", err),
+			Code:    facebook.ErrCodeUnknown,
+		})
+		return
+	}
+
+	req := &Request{
+		Method: r.Method,
+		Path:   normalizePath(r.URL.Path),
+		Params: facebook.Params{},
+		Header: r.Header,
+	}
+
+	for k := range r.Form {
+		req.Params[k] = r.Form.Get(k)
+	}
+
+	if r.MultipartForm != nil {
+		for k := range r.MultipartForm.File {
+			req.Params[k] = "@" + r.MultipartForm.File[k][0].Filename
+		}
+	}
+
+	req.resolve()
+
+	if auth := r.Header.Get("Authorization"); auth != "" && req.AccessToken == "" {
+		if i := strings.IndexByte(auth, ' '); i > 0 {
+			req.AccessToken = auth[i+1:]
+		}
+	}
+
+	if req.Method == "POST" && req.Path == "/" {
+		if batch, ok := req.Params["batch"].(string); ok {
+			s.record(req)
+			s.serveBatch(w, req, batch)
+			return
+		}
+	}
+
+	res, err := s.dispatch(req)
+
+	status := http.StatusOK
+
+	if err != nil {
+		status = errorStatus(err)
+	}
+
+	s.writeResult(w, status, res, err)
+}
+
+// resolve sets method override and access token from params.
+func (req *Request) resolve() {
+	if method, ok := req.Params["method"].(string); ok && req.Method == "POST" {
+		req.Method = strings.ToUpper(method)
+		delete(req.Params, "method")
+	}
+
+	if token, ok := req.Params["access_token"].(string); ok {
+		req.AccessToken = token
+		delete(req.Params, "access_token")
+	}
+}
+
+func (s *Server) serveBatch(w http.ResponseWriter, batchReq *Request, batch string) {
+	var ops []struct {
+		Method      string `json:"method"`
+		RelativeURL string `json:"relative_url"`
+		Body        string `json:"body"`
+	}
+
+	if err := json.Unmarshal([]byte(batch), &ops); err != nil {
+		s.writeResult(w, http.StatusBadRequest, nil, &facebook.Error{
+			Message: fmt.Sprintf("fbtest: invalid batch param; %v", err),
+			Code:    facebook.ErrCodeUnknown,
+		})
+		return
+	}
+
+	results := make([]interface{}, 0, len(ops))
+
+	for _, op := range ops {
+		u, err := url.Parse(op.RelativeURL)
+
+		if err != nil {
+			results = append(results, nil)
+			continue
+		}
+
+		req := &Request{
+			Method:      strings.ToUpper(op.Method),
+			Path:        normalizePath(u.Path),
+			Params:      facebook.Params{},
+			AccessToken: batchReq.AccessToken,
+			Batch:       true,
+		}
+
+		if req.Method == "" {
+			req.Method = "GET"
+		}
+
+		body, _ := url.ParseQuery(op.Body)
+
+		for _, values := range []url.Values{u.Query(), body} {
+			for k := range values {
+				req.Params[k] = values.Get(k)
+			}
+		}
+
+		req.resolve()
+		res, err := s.dispatch(req)
+		code := http.StatusOK
+
+		if err != nil {
+			code = errorStatus(err)
+			res = errorResult(err)
+		}
+
+		data, _ := json.Marshal(res)
+		results = append(results, facebook.Result{
+			"code": code,
+			"headers": []facebook.Result{
+				{"name": "Content-Type", "value": "text/javascript; charset=UTF-8"},
+			},
+			"body": string(data),
+		})
+	}
+
+	s.writeJSON(w, http.StatusOK, results)
+}
+
+func (s *Server) dispatch(req *Request) (facebook.Result, error) {
+	s.record(req)
+
+	s.mu.RLock()
+	route := s.routes[req.Method+" "+req.Path]
+	s.mu.RUnlock()
+
+	if route == nil {
+		return nil, &facebook.Error{
+			Message:      fmt.Sprintf("fbtest: unsupported %v request to %v", req.Method, req.Path),
+			Type:         "GraphMethodException",
+			Code:         100,
+			ErrorSubcode: 33,
+		}
+	}
+
+	route.mu.Lock()
+	route.calls++
+	route.mu.Unlock()
+
+	return route.handler(req)
+}
+
+func (s *Server) record(req *Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = append(s.requests, req)
+}
+
+func (s *Server) writeResult(w http.ResponseWriter, status int, res facebook.Result, err error) {
+	if err != nil {
+		s.writeJSON(w, status, errorResult(err))
+		return
+	}
+
+	if res == nil {
+		res = facebook.Result{"success": true}
+	}
+
+	s.writeJSON(w, status, res)
+}
+
+func (s *Server) writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	s.mu.RLock()
+	usage := s.usage
+	s.mu.RUnlock()
+
+	if usage != nil {
+		setUsageHeaders(w.Header(), usage)
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func setUsageHeaders(header http.Header, usage *facebook.UsageInfo) {
+	set := func(name string, v, zero interface{}) {
+		if reflect.DeepEqual(v, zero) {
+			return
+		}
+
+		data, _ := json.Marshal(v)
+		header.Set(name, string(data))
+	}
+
+	set(appUsageHeader, usage.App, facebook.RateLimiting{})
+	set(pageUsageHeader, usage.Page, facebook.RateLimiting{})
+	set(adAccountUsageHeader, usage.AdAccount, facebook.AdAccountUsage{})
+	set(adsInsightsThrottleHeader, usage.AdsInsights, facebook.AdsInsightsThrottle{})
+
+	if len(usage.BusinessUseCase) != 0 {
+		set(businessUseCaseUsageHeader, usage.BusinessUseCase, nil)
+	}
+}
+
+func errorStatus(err error) int {
+	if _, ok := err.(*facebook.Error); ok {
+		return http.StatusBadRequest
+	}
+
+	return http.StatusInternalServerError
+}
+
+func errorResult(err error) facebook.Result {
+	e, ok := err.(*facebook.Error)
+
+	if !ok {
+		e = &facebook.Error{
+			Message: err.Error(),
+			Code:    facebook.ErrCodeUnknown,
+		}
+	}
+
+	data := facebook.Result{
+		"message": e.Message,
+		"code":    e.Code,
+	}
+
+	if e.Type != "" {
+		data["type"] = e.Type
+	}
+
+	if e.ErrorSubcode != 0 {
+		data["error_subcode"] = e.ErrorSubcode
+	}
+
+	if e.UserTitle != "" {
+		data["error_user_title"] = e.UserTitle
+	}
+
+	if e.UserMessage != "" {
+		data["error_user_msg"] = e.UserMessage
+	}
+
+	if e.IsTransient {
+		data["is_transient"] = true
+	}
+
+	if e.TraceID != "" {
+		data["fbtrace_id"] = e.TraceID
+	}
+
+	return facebook.Result{"error": data}
+}
+
+// normalizePath removes version prefix and makes sure path starts with "/".
+func normalizePath(path string) string {
+	path = strings.TrimPrefix(path, "/")
+	path = regexpVersionPrefix.ReplaceAllString(path, "")
+	return "/" + path
+}
+
+func paramString(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+
+	data, err := json.Marshal(v)
+
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+
+	return string(data)
+}
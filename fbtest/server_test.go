@@ -0,0 +1,173 @@
+// A facebook graph api client in go.
+// https://github.com/huandu/facebook/
+//
+// Copyright 2012, Huan Du
+// Licensed under the MIT license
+// https://github.com/huandu/facebook/blob/master/LICENSE
+
+package fbtest
+
+import (
+	"testing"
+
+	"github.com/huandu/facebook/v2"
+)
+
+func TestServerRespond(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	route := server.Respond("GET", "/me", facebook.Result{"id": "123", "name": "Jane"})
+	server.RespondError("POST", "/me/feed", &facebook.Error{
+		Message:      "invalid token",
+		Type:         "OAuthException",
+		Code:         190,
+		ErrorSubcode: 463,
+	})
+	server.SetUsage(&facebook.UsageInfo{
+		App: facebook.RateLimiting{CallCount: 42},
+	})
+
+	session := server.Session("token")
+	session.Version = "v19.0"
+	res, err := session.Get("/me", facebook.Params{"fields": "id,name", "limit": 10})
+
+	if err != nil {
+		t.Fatalf("fail to get /me. [e:%v]", err)
+	}
+
+	if res.Get("name") != "Jane" || res.UsageInfo().App.CallCount != 42 {
+		t.Fatalf("invalid result. [res:%v]", res)
+	}
+
+	req := server.LastRequest("GET", "/me")
+
+	if req.AccessToken != "token" || route.Calls() != 1 {
+		t.Fatalf("invalid request. [req:%#v] [calls:%v]", req, route.Calls())
+	}
+
+	req.AssertParams(t, facebook.Params{"fields": "id,name", "limit": 10})
+
+	_, err = session.Post("/me/feed", facebook.Params{"message": "hello"})
+	e, ok := err.(*facebook.Error)
+
+	if !ok || e.Code != 190 || e.ErrorSubcode != 463 || e.Type != "OAuthException" {
+		t.Fatalf("invalid error. [e:%v]", err)
+	}
+
+	server.LastRequest("POST", "/me/feed").AssertParams(t, facebook.Params{"message": "hello"})
+
+	if _, err := session.Delete("/123", nil); err == nil {
+		t.Fatalf("unregistered route must fail.")
+	}
+
+	if req := server.LastRequest("DELETE", "/123"); req == nil {
+		t.Fatalf("DELETE must be recorded.")
+	}
+
+	if len(server.Requests()) != 3 {
+		t.Fatalf("invalid requests. [count:%v]", len(server.Requests()))
+	}
+}
+
+func TestServerPaging(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	server.RespondPages("GET", "/me/accounts",
+		[]interface{}{facebook.Result{"id": "1"}, facebook.Result{"id": "2"}},
+		[]interface{}{facebook.Result{"id": "3"}},
+	)
+
+	session := server.Session("token")
+	res, err := session.Get("/me/accounts", facebook.Params{"fields": "id"})
+
+	if err != nil {
+		t.Fatalf("fail to get accounts. [e:%v]", err)
+	}
+
+	pr, err := res.Paging(session)
+
+	if err != nil {
+		t.Fatalf("fail to create paging. [e:%v]", err)
+	}
+
+	ids := []string{}
+
+	for {
+		for _, item := range pr.Data() {
+			ids = append(ids, item.Get("id").(string))
+		}
+
+		noMore, err := pr.Next()
+
+		if err != nil {
+			t.Fatalf("fail to get next page. [e:%v]", err)
+		}
+
+		if noMore {
+			break
+		}
+	}
+
+	if len(ids) != 3 || ids[2] != "3" {
+		t.Fatalf("invalid ids. [ids:%v]", ids)
+	}
+
+	req := server.LastRequest("GET", "/me/accounts")
+	req.AssertParams(t, facebook.Params{"fields": "id", "after": "1"})
+
+	if req.AccessToken != "token" {
+		t.Fatalf("next url must keep access token. [token:%v]", req.AccessToken)
+	}
+}
+
+func TestServerBatch(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	server.Handle("GET", "/me", func(req *Request) (facebook.Result, error) {
+		return facebook.Result{"id": "123", "token": req.AccessToken}, nil
+	})
+	server.Respond("POST", "/me/feed", facebook.Result{"id": "123_456"})
+
+	session := server.Session("token")
+	results, err := session.BatchApi(
+		facebook.Params{"method": facebook.GET, "relative_url": "me?fields=id"},
+		facebook.Params{"method": facebook.POST, "relative_url": "me/feed", "body": "message=hello"},
+		facebook.Params{"method": facebook.GET, "relative_url": "unknown"},
+	)
+
+	if err != nil {
+		t.Fatalf("fail to send batch. [e:%v]", err)
+	}
+
+	if len(results) != 3 {
+		t.Fatalf("invalid batch results. [results:%v]", results)
+	}
+
+	batch := make([]*facebook.BatchResult, len(results))
+
+	for i, res := range results {
+		if batch[i], err = res.Batch(); err != nil {
+			t.Fatalf("fail to decode batch result. [i:%v] [e:%v]", i, err)
+		}
+	}
+
+	if batch[0].Result.Get("id") != "123" || batch[0].Result.Get("token") != "token" || batch[1].Result.Get("id") != "123_456" {
+		t.Fatalf("invalid batch results. [results:%v]", results)
+	}
+
+	if batch[2].StatusCode != 400 || batch[2].Result.Err() == nil {
+		t.Fatalf("unknown route in batch must fail.")
+	}
+
+	req := server.LastRequest("POST", "/me/feed")
+
+	if !req.Batch {
+		t.Fatalf("batch operation must be recorded.")
+	}
+
+	req.AssertParams(t, facebook.Params{"message": "hello"})
+	server.LastRequest("GET", "/me").AssertParams(t, facebook.Params{"fields": "id"})
+}
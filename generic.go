@@ -0,0 +1,188 @@
+// A facebook graph api client in go.
+// https://github.com/huandu/facebook/
+//
+// Copyright 2012, Huan Du
+// Licensed under the MIT license
+// https://github.com/huandu/facebook/blob/master/LICENSE
+
+package facebook
+
+// Response is the envelope of a typed graph api response.
+type Response struct {
+	Result Result // the raw result.
+}
+
+// UsageInfo returns usage information in response. See Result#UsageInfo.
+func (resp *Response) UsageInfo() *UsageInfo {
+	return resp.Result.UsageInfo()
+}
+
+// DebugInfo returns debug information in response. See Result#DebugInfo.
+func (resp *Response) DebugInfo() *DebugInfo {
+	return resp.Result.DebugInfo()
+}
+
+// DecodeAs decodes res to a value of type T. See Result#Decode.
+//
+//	user, err := facebook.DecodeAs[User](res)
+func DecodeAs[T any](res Result) (v T, err error) {
+	err = res.Decode(&v)
+	return
+}
+
+// GetAs sends a GET request and decodes the result to a value of type T.
+//
+// The returned Response is nil only if facebook is not reachable.
+// If facebook returns an error, err is set and the error result is kept in Response.
+//
+//	user, resp, err := facebook.GetAs[User](session, "/me", facebook.Params{"fields": "id,name"})
+func GetAs[T any](session *Session, path string, params Params) (v T, resp *Response, err error) {
+	var res Result
+	res, err = session.Get(path, params)
+
+	if res != nil {
+		resp = &Response{
+			Result: res,
+		}
+	}
+
+	if err != nil {
+		return
+	}
+
+	v, err = DecodeAs[T](res)
+	return
+}
+
+// Pager iterates all items in a paging api and decodes them to type T.
+//
+//	pager, err := facebook.GetPager[Post](session, "/me/feed", nil)
+//
+//	for pager.Next() {
+//	    post := pager.Item()
+//	}
+//
+//	if err := pager.Err(); err != nil {
+//	    // handle error.
+//	}
+type Pager[T any] struct {
+	pr    *PagingResult
+	items []T
+	index int
+	item  T
+	err   error
+	done  bool
+}
+
+// NewPager creates a Pager with a paging api result. See Result#Paging.
+func NewPager[T any](session *Session, res Result) (*Pager[T], error) {
+	pr, err := res.Paging(session)
+
+	if err != nil {
+		return nil, err
+	}
+
+	pager := &Pager[T]{
+		pr: pr,
+	}
+
+	if err = pager.decodePage(); err != nil {
+		return nil, err
+	}
+
+	return pager, nil
+}
+
+// GetPager sends a GET request to a paging api and creates a Pager with the result.
+func GetPager[T any](session *Session, path string, params Params) (*Pager[T], error) {
+	res, err := session.Get(path, params)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPager[T](session, res)
+}
+
+// Next moves to the next item and fetches next page if necessary.
+// It returns false when there is no more item or an error occurs.
+func (pager *Pager[T]) Next() bool {
+	for pager.err == nil && !pager.done {
+		if pager.index < len(pager.items) {
+			pager.item = pager.items[pager.index]
+			pager.index++
+			return true
+		}
+
+		if !pager.pr.HasNext() {
+			pager.done = true
+			break
+		}
+
+		noMore, err := pager.pr.Next()
+
+		if err != nil {
+			pager.err = err
+			break
+		}
+
+		if err = pager.decodePage(); err != nil {
+			pager.err = err
+			break
+		}
+
+		// facebook may return an empty page without next url at the end.
+		if noMore && len(pager.items) == 0 {
+			pager.done = true
+		}
+	}
+
+	var zero T
+	pager.item = zero
+	return false
+}
+
+// Item returns current item.
+func (pager *Pager[T]) Item() T {
+	return pager.item
+}
+
+// Err returns the error occurred in Next.
+func (pager *Pager[T]) Err() error {
+	return pager.err
+}
+
+// UsageInfo returns usage information of the latest page.
+func (pager *Pager[T]) UsageInfo() *UsageInfo {
+	return pager.pr.UsageInfo()
+}
+
+// All reads all remaining items.
+func (pager *Pager[T]) All() ([]T, error) {
+	var items []T
+
+	for pager.Next() {
+		items = append(items, pager.Item())
+	}
+
+	return items, pager.Err()
+}
+
+func (pager *Pager[T]) decodePage() error {
+	data := pager.pr.Data()
+	items := make([]T, 0, len(data))
+
+	for _, res := range data {
+		item, err := DecodeAs[T](res)
+
+		if err != nil {
+			return err
+		}
+
+		items = append(items, item)
+	}
+
+	pager.items = items
+	pager.index = 0
+	return nil
+}
@@ -0,0 +1,107 @@
+// A facebook graph api client in go.
+// https://github.com/huandu/facebook/
+//
+// Copyright 2012, Huan Du
+// Licensed under the MIT license
+// https://github.com/huandu/facebook/blob/master/LICENSE
+
+package facebook
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type testGenericUser struct {
+	ID   string `facebook:",required"`
+	Name string
+}
+
+func newTestGenericServer() *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-App-Usage", `{"call_count":3}`)
+
+		switch r.URL.Path {
+		case "/me":
+			fmt.Fprint(w, `{"id":"1","name":"Jane"}`)
+		case "/error":
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":{"message":"bad","code":100}}`)
+		case "/friends":
+			switch r.URL.Query().Get("after") {
+			case "":
+				fmt.Fprintf(w, `{"data":[{"id":"1"},{"id":"2"}],"paging":{"next":"%v/friends?after=2"}}`, server.URL)
+			case "2":
+				fmt.Fprintf(w, `{"data":[{"id":"3","name":"Joe"}],"paging":{"next":"%v/friends?after=3"}}`, server.URL)
+			default:
+				fmt.Fprint(w, `{"data":[]}`)
+			}
+		case "/invalid":
+			fmt.Fprint(w, `{"data":[{"name":"no id"}]}`)
+		}
+	}))
+	return server
+}
+
+func TestGetAs(t *testing.T) {
+	server := newTestGenericServer()
+	defer server.Close()
+
+	session := &Session{BaseURL: server.URL + "/"}
+	user, resp, err := GetAs[testGenericUser](session, "/me", nil)
+
+	if err != nil {
+		t.Fatalf("fail to get user. [e:%v]", err)
+	}
+
+	if user.ID != "1" || user.Name != "Jane" || resp.UsageInfo().App.CallCount != 3 {
+		t.Fatalf("invalid user. [user:%#v]", user)
+	}
+
+	_, resp, err = GetAs[testGenericUser](session, "/error", nil)
+
+	if e, ok := err.(*Error); !ok || e.Code != 100 || resp == nil || resp.Result.Err() == nil {
+		t.Fatalf("graph api error must be returned. [e:%v]", err)
+	}
+
+	if _, err = DecodeAs[testGenericUser](Result{"name": "no id"}); err == nil {
+		t.Fatalf("required field must be checked.")
+	}
+}
+
+func TestPager(t *testing.T) {
+	server := newTestGenericServer()
+	defer server.Close()
+
+	session := &Session{BaseURL: server.URL + "/"}
+	pager, err := GetPager[testGenericUser](session, "/friends", nil)
+
+	if err != nil {
+		t.Fatalf("fail to create pager. [e:%v]", err)
+	}
+
+	users, err := pager.All()
+
+	if err != nil {
+		t.Fatalf("fail to read all items. [e:%v]", err)
+	}
+
+	if len(users) != 3 || users[2].ID != "3" || users[2].Name != "Joe" {
+		t.Fatalf("invalid users. [users:%v]", users)
+	}
+
+	if pager.Next() || pager.UsageInfo() == nil {
+		t.Fatalf("pager must be done.")
+	}
+
+	if _, err := GetPager[testGenericUser](session, "/invalid", nil); err == nil {
+		t.Fatalf("invalid item must fail.")
+	}
+
+	if _, err := GetPager[testGenericUser](session, "/me", nil); err == nil {
+		t.Fatalf("non-paging result must fail.")
+	}
+}
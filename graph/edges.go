@@ -0,0 +1,110 @@
+// A facebook graph api client in go.
+// https://github.com/huandu/facebook/
+//
+// Copyright 2012, Huan Du
+// Licensed under the MIT license
+// https://github.com/huandu/facebook/blob/master/LICENSE
+
+package graph
+
+import "github.com/huandu/facebook/v2"
+
+// Accounts reads pages managed by the user.
+func (u *User) Accounts(session *facebook.Session, opts *EdgeOptions) (*facebook.Pager[Page], error) {
+	return getEdge[Page](session, u.ID, "accounts", opts, append(append([]Field{}, DefaultPageFields...), FieldAccessToken))
+}
+
+// Posts reads posts published by the user.
+func (u *User) Posts(session *facebook.Session, opts *EdgeOptions) (*facebook.Pager[Post], error) {
+	return getEdge[Post](session, u.ID, "posts", opts, DefaultPostFields)
+}
+
+// Photos reads photos of the user.
+func (u *User) Photos(session *facebook.Session, opts *EdgeOptions) (*facebook.Pager[Photo], error) {
+	return getEdge[Photo](session, u.ID, "photos", opts, DefaultPhotoFields)
+}
+
+// Videos reads videos of the user.
+func (u *User) Videos(session *facebook.Session, opts *EdgeOptions) (*facebook.Pager[Video], error) {
+	return getEdge[Video](session, u.ID, "videos", opts, DefaultVideoFields)
+}
+
+// Albums reads photo albums of the user.
+func (u *User) Albums(session *facebook.Session, opts *EdgeOptions) (*facebook.Pager[Album], error) {
+	return getEdge[Album](session, u.ID, "albums", opts, DefaultAlbumFields)
+}
+
+// Posts reads posts published by the page.
+func (p *Page) Posts(session *facebook.Session, opts *EdgeOptions) (*facebook.Pager[Post], error) {
+	return getEdge[Post](session, p.ID, "posts", opts, DefaultPostFields)
+}
+
+// Feed reads posts in the feed of the page, including posts by others.
+func (p *Page) Feed(session *facebook.Session, opts *EdgeOptions) (*facebook.Pager[Post], error) {
+	return getEdge[Post](session, p.ID, "feed", opts, DefaultPostFields)
+}
+
+// Photos reads photos of the page.
+func (p *Page) Photos(session *facebook.Session, opts *EdgeOptions) (*facebook.Pager[Photo], error) {
+	return getEdge[Photo](session, p.ID, "photos", opts, DefaultPhotoFields)
+}
+
+// Videos reads videos of the page.
+func (p *Page) Videos(session *facebook.Session, opts *EdgeOptions) (*facebook.Pager[Video], error) {
+	return getEdge[Video](session, p.ID, "videos", opts, DefaultVideoFields)
+}
+
+// Albums reads photo albums of the page.
+func (p *Page) Albums(session *facebook.Session, opts *EdgeOptions) (*facebook.Pager[Album], error) {
+	return getEdge[Album](session, p.ID, "albums", opts, DefaultAlbumFields)
+}
+
+// Events reads events of the page.
+func (p *Page) Events(session *facebook.Session, opts *EdgeOptions) (*facebook.Pager[Event], error) {
+	return getEdge[Event](session, p.ID, "events", opts, DefaultEventFields)
+}
+
+// Comments reads comments on the post.
+func (p *Post) Comments(session *facebook.Session, opts *EdgeOptions) (*facebook.Pager[Comment], error) {
+	return getEdge[Comment](session, p.ID, "comments", opts, DefaultCommentFields)
+}
+
+// Reactions reads reactions to the post.
+func (p *Post) Reactions(session *facebook.Session, opts *EdgeOptions) (*facebook.Pager[Reaction], error) {
+	return getEdge[Reaction](session, p.ID, "reactions", opts, DefaultReactionFields)
+}
+
+// Comments reads comments on the photo.
+func (p *Photo) Comments(session *facebook.Session, opts *EdgeOptions) (*facebook.Pager[Comment], error) {
+	return getEdge[Comment](session, p.ID, "comments", opts, DefaultCommentFields)
+}
+
+// Reactions reads reactions to the photo.
+func (p *Photo) Reactions(session *facebook.Session, opts *EdgeOptions) (*facebook.Pager[Reaction], error) {
+	return getEdge[Reaction](session, p.ID, "reactions", opts, DefaultReactionFields)
+}
+
+// Comments reads comments on the video.
+func (v *Video) Comments(session *facebook.Session, opts *EdgeOptions) (*facebook.Pager[Comment], error) {
+	return getEdge[Comment](session, v.ID, "comments", opts, DefaultCommentFields)
+}
+
+// Reactions reads reactions to the video.
+func (v *Video) Reactions(session *facebook.Session, opts *EdgeOptions) (*facebook.Pager[Reaction], error) {
+	return getEdge[Reaction](session, v.ID, "reactions", opts, DefaultReactionFields)
+}
+
+// Comments reads replies to the comment.
+func (c *Comment) Comments(session *facebook.Session, opts *EdgeOptions) (*facebook.Pager[Comment], error) {
+	return getEdge[Comment](session, c.ID, "comments", opts, DefaultCommentFields)
+}
+
+// Reactions reads reactions to the comment.
+func (c *Comment) Reactions(session *facebook.Session, opts *EdgeOptions) (*facebook.Pager[Reaction], error) {
+	return getEdge[Reaction](session, c.ID, "reactions", opts, DefaultReactionFields)
+}
+
+// Photos reads photos in the album.
+func (a *Album) Photos(session *facebook.Session, opts *EdgeOptions) (*facebook.Pager[Photo], error) {
+	return getEdge[Photo](session, a.ID, "photos", opts, DefaultPhotoFields)
+}
@@ -0,0 +1,110 @@
+// A facebook graph api client in go.
+// https://github.com/huandu/facebook/
+//
+// Copyright 2012, Huan Du
+// Licensed under the MIT license
+// https://github.com/huandu/facebook/blob/master/LICENSE
+
+package graph
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Field is a field name used in "fields" param.
+// Use Fields to build the param value.
+//
+//	graph.Fields(graph.FieldID, graph.FieldPicture.With(graph.FieldURL), graph.FieldComments.Limit(5))
+//	// "id,picture{url},comments.limit(5)"
+type Field string
+
+// Fields of core nodes.
+const (
+	FieldID              Field = "id"
+	FieldName            Field = "name"
+	FieldFirstName       Field = "first_name"
+	FieldMiddleName      Field = "middle_name"
+	FieldLastName        Field = "last_name"
+	FieldShortName       Field = "short_name"
+	FieldEmail           Field = "email"
+	FieldBirthday        Field = "birthday"
+	FieldLink            Field = "link"
+	FieldPicture         Field = "picture"
+	FieldURL             Field = "url"
+	FieldWidth           Field = "width"
+	FieldHeight          Field = "height"
+	FieldIsSilhouette    Field = "is_silhouette"
+	FieldCategory        Field = "category"
+	FieldAbout           Field = "about"
+	FieldUsername        Field = "username"
+	FieldWebsite         Field = "website"
+	FieldFanCount        Field = "fan_count"
+	FieldFollowersCount  Field = "followers_count"
+	FieldCover           Field = "cover"
+	FieldAccessToken     Field = "access_token"
+	FieldMessage         Field = "message"
+	FieldStory           Field = "story"
+	FieldCreatedTime     Field = "created_time"
+	FieldUpdatedTime     Field = "updated_time"
+	FieldPermalinkURL    Field = "permalink_url"
+	FieldFrom            Field = "from"
+	FieldFullPicture     Field = "full_picture"
+	FieldStatusType      Field = "status_type"
+	FieldShares          Field = "shares"
+	FieldAlbum           Field = "album"
+	FieldImages          Field = "images"
+	FieldTitle           Field = "title"
+	FieldDescription     Field = "description"
+	FieldSource          Field = "source"
+	FieldLength          Field = "length"
+	FieldLikeCount       Field = "like_count"
+	FieldCommentCount    Field = "comment_count"
+	FieldParent          Field = "parent"
+	FieldCount           Field = "count"
+	FieldCoverPhoto      Field = "cover_photo"
+	FieldStartTime       Field = "start_time"
+	FieldEndTime         Field = "end_time"
+	FieldTimezone        Field = "timezone"
+	FieldPlace           Field = "place"
+	FieldAttendingCount  Field = "attending_count"
+	FieldInterestedCount Field = "interested_count"
+	FieldIsOnline        Field = "is_online"
+	FieldType            Field = "type"
+	FieldComments        Field = "comments"
+	FieldReactions       Field = "reactions"
+)
+
+// Default fields of nodes used when reading edges.
+var (
+	DefaultUserFields     = []Field{FieldID, FieldName, FieldFirstName, FieldLastName, FieldPicture}
+	DefaultPageFields     = []Field{FieldID, FieldName, FieldCategory, FieldLink, FieldFanCount, FieldPicture}
+	DefaultPostFields     = []Field{FieldID, FieldMessage, FieldStory, FieldCreatedTime, FieldUpdatedTime, FieldPermalinkURL, FieldFrom, FieldFullPicture}
+	DefaultPhotoFields    = []Field{FieldID, FieldName, FieldLink, FieldCreatedTime, FieldFrom, FieldImages, FieldWidth, FieldHeight}
+	DefaultVideoFields    = []Field{FieldID, FieldTitle, FieldDescription, FieldSource, FieldPermalinkURL, FieldLength, FieldCreatedTime, FieldUpdatedTime, FieldFrom}
+	DefaultCommentFields  = []Field{FieldID, FieldMessage, FieldCreatedTime, FieldFrom, FieldLikeCount, FieldCommentCount, FieldPermalinkURL}
+	DefaultAlbumFields    = []Field{FieldID, FieldName, FieldDescription, FieldCount, FieldCreatedTime, FieldLink, FieldCoverPhoto}
+	DefaultEventFields    = []Field{FieldID, FieldName, FieldDescription, FieldStartTime, FieldEndTime, FieldTimezone, FieldPlace, FieldAttendingCount, FieldInterestedCount, FieldIsOnline}
+	DefaultReactionFields = []Field{FieldID, FieldName, FieldType}
+)
+
+// With returns the field with sub-fields, e.g. "picture{url,width}".
+func (f Field) With(fields ...Field) Field {
+	return Field(fmt.Sprintf("%v{%v}", f, Fields(fields...)))
+}
+
+// Limit returns the field with a limit modifier, e.g. "comments.limit(5)".
+func (f Field) Limit(n int) Field {
+	return Field(fmt.Sprintf("%v.limit(%v)", f, n))
+}
+
+// Fields joins fields with comma. The result can be used as "fields" param.
+func Fields(fields ...Field) string {
+	strs := make([]string, 0, len(fields))
+
+	for _, f := range fields {
+		strs = append(strs, string(f))
+	}
+
+	return strings.Join(strs, ",")
+}
@@ -0,0 +1,153 @@
+// A facebook graph api client in go.
+// https://github.com/huandu/facebook/
+//
+// Copyright 2012, Huan Du
+// Licensed under the MIT license
+// https://github.com/huandu/facebook/blob/master/LICENSE
+
+// Package graph provides typed models of core Graph API nodes and their edges.
+//
+// All models can be decoded from facebook.Result by Result#Decode.
+//
+//	page, err := graph.Get[graph.Page](session, "123", graph.FieldID, graph.FieldName, graph.FieldFanCount)
+//	posts, err := page.Posts(session, &graph.EdgeOptions{Limit: 25})
+//
+//	for posts.Next() {
+//	    post := posts.Item()
+//	}
+package graph
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/huandu/facebook/v2"
+)
+
+// Time layouts used by Graph API.
+const (
+	TimeLayout = "2006-01-02T15:04:05-0700" // the default time format in Graph API responses.
+	DateLayout = "2006-01-02"               // the format of a date without time.
+)
+
+// Time is a time in Graph API responses.
+// It can be decoded from TimeLayout, RFC3339, DateLayout or a unix timestamp.
+type Time struct {
+	time.Time
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (t *Time) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		t.Time = time.Time{}
+		return nil
+	}
+
+	if len(data) > 0 && data[0] != '"' {
+		n, err := strconv.ParseInt(string(data), 10, 64)
+
+		if err != nil {
+			return fmt.Errorf("graph: invalid unix timestamp %s", data)
+		}
+
+		t.Time = time.Unix(n, 0)
+		return nil
+	}
+
+	var s string
+
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	if s == "" {
+		t.Time = time.Time{}
+		return nil
+	}
+
+	for _, layout := range []string{TimeLayout, time.RFC3339, DateLayout} {
+		if parsed, err := time.Parse(layout, s); err == nil {
+			t.Time = parsed
+			return nil
+		}
+	}
+
+	return fmt.Errorf("graph: invalid time '%v'", s)
+}
+
+// MarshalJSON implements json.Marshaler. Time is encoded in TimeLayout.
+func (t Time) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte(`""`), nil
+	}
+
+	return json.Marshal(t.Format(TimeLayout))
+}
+
+// Get gets a node by id and decodes it to T.
+// If fields is empty, Graph API returns default fields of the node.
+func Get[T any](session *facebook.Session, id string, fields ...Field) (*T, error) {
+	var params facebook.Params
+
+	if len(fields) != 0 {
+		params = facebook.Params{
+			"fields": Fields(fields...),
+		}
+	}
+
+	node, _, err := facebook.GetAs[T](session, "/"+strings.TrimPrefix(id, "/"), params)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &node, nil
+}
+
+// EdgeOptions are options to read an edge.
+type EdgeOptions struct {
+	Fields []Field         // fields of items. Default fields of the item type are used if it's empty.
+	Limit  int             // page size. Graph API decides page size if it's 0.
+	Since  time.Time       // read items created after Since if it's not zero.
+	Until  time.Time       // read items created before Until if it's not zero.
+	Extra  facebook.Params // extra params.
+}
+
+// getEdge reads an edge of node id with a typed pager.
+func getEdge[T any](session *facebook.Session, id, edge string, opts *EdgeOptions, defaultFields []Field) (*facebook.Pager[T], error) {
+	params := facebook.Params{}
+
+	if opts == nil {
+		opts = &EdgeOptions{}
+	}
+
+	for k, v := range opts.Extra {
+		params[k] = v
+	}
+
+	fields := opts.Fields
+
+	if len(fields) == 0 {
+		fields = defaultFields
+	}
+
+	params["fields"] = Fields(fields...)
+
+	if opts.Limit > 0 {
+		params["limit"] = opts.Limit
+	}
+
+	if !opts.Since.IsZero() {
+		params["since"] = opts.Since.Unix()
+	}
+
+	if !opts.Until.IsZero() {
+		params["until"] = opts.Until.Unix()
+	}
+
+	return facebook.GetPager[T](session, fmt.Sprintf("/%v/%v", id, edge), params)
+}
@@ -0,0 +1,197 @@
+// A facebook graph api client in go.
+// https://github.com/huandu/facebook/
+//
+// Copyright 2012, Huan Du
+// Licensed under the MIT license
+// https://github.com/huandu/facebook/blob/master/LICENSE
+
+package graph
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/huandu/facebook/v2"
+	"github.com/huandu/facebook/v2/fbtest"
+)
+
+func loadFixture(t *testing.T, name string) facebook.Result {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+
+	if err != nil {
+		t.Fatalf("fail to read fixture. [name:%v] [e:%v]", name, err)
+	}
+
+	res, err := facebook.MakeResult(data)
+
+	if err != nil {
+		t.Fatalf("fail to parse fixture. [name:%v] [e:%v]", name, err)
+	}
+
+	return res
+}
+
+func decodeFixture[T any](t *testing.T, name string) *T {
+	t.Helper()
+
+	node, err := facebook.DecodeAs[T](loadFixture(t, name))
+
+	if err != nil {
+		t.Fatalf("fail to decode fixture. [name:%v] [e:%v]", name, err)
+	}
+
+	return &node
+}
+
+func TestDecodeNodes(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	user := decodeFixture[User](t, "user.json")
+
+	if user.ID != "100001" || user.FirstName != "Jane" || user.Birthday != "01/02/1990" ||
+		user.Picture == nil || user.Picture.Data.URL != "https://example.com/jane.jpg" || user.Picture.Data.Width != 50 {
+		t.Fatalf("invalid user. [user:%#v]", user)
+	}
+
+	page := decodeFixture[Page](t, "page.json")
+
+	if page.FanCount != 12345 || page.FollowersCount != 23456 || page.Cover == nil || page.Cover.OffsetY != 50 || page.AccessToken != "page-token" {
+		t.Fatalf("invalid page. [page:%#v]", page)
+	}
+
+	post := decodeFixture[Post](t, "post.json")
+
+	if !post.CreatedTime.Equal(created) || post.From.ID != "200001" || post.Shares.Count != 7 ||
+		post.PermalinkURL == "" || post.FullPicture == "" || post.StatusType != "added_photos" {
+		t.Fatalf("invalid post. [post:%#v]", post)
+	}
+
+	photo := decodeFixture[Photo](t, "photo.json")
+
+	if len(photo.Images) != 2 || photo.Images[1].Width != 640 || photo.Album.Name != "Timeline Photos" || !photo.CreatedTime.Equal(created) {
+		t.Fatalf("invalid photo. [photo:%#v]", photo)
+	}
+
+	video := decodeFixture[Video](t, "video.json")
+
+	if video.Length != 12.5 || video.Title != "A video" || video.From.Name != "Example Page" {
+		t.Fatalf("invalid video. [video:%#v]", video)
+	}
+
+	comment := decodeFixture[Comment](t, "comment.json")
+
+	if comment.LikeCount != 3 || comment.Parent == nil || comment.Parent.Message != "First!" {
+		t.Fatalf("invalid comment. [comment:%#v]", comment)
+	}
+
+	album := decodeFixture[Album](t, "album.json")
+
+	if album.Count != 42 || album.CoverPhoto == nil || album.CoverPhoto.ID != "400001" {
+		t.Fatalf("invalid album. [album:%#v]", album)
+	}
+
+	event := decodeFixture[Event](t, "event.json")
+
+	if event.StartTime.UTC() != time.Date(2024, 6, 2, 2, 0, 0, 0, time.UTC) || event.Place.Location.City != "Menlo Park" ||
+		event.Place.Location.Latitude != 37.4847 || event.AttendingCount != 120 || event.IsOnline {
+		t.Fatalf("invalid event. [event:%#v]", event)
+	}
+
+	var reactions []Reaction
+
+	if err := loadFixture(t, "reactions.json").DecodeField("data", &reactions); err != nil {
+		t.Fatalf("fail to decode reactions. [e:%v]", err)
+	}
+
+	if len(reactions) != 2 || reactions[0].Type != ReactionLove {
+		t.Fatalf("invalid reactions. [reactions:%v]", reactions)
+	}
+}
+
+func TestTime(t *testing.T) {
+	expected := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	for _, data := range []string{`"2024-01-02T03:04:05+0000"`, `"2024-01-02T03:04:05Z"`, `1704164645`} {
+		var tm Time
+
+		if err := json.Unmarshal([]byte(data), &tm); err != nil || !tm.Equal(expected) {
+			t.Fatalf("invalid time. [data:%v] [time:%v] [e:%v]", data, tm, err)
+		}
+	}
+
+	var tm Time
+
+	if err := json.Unmarshal([]byte(`"not a time"`), &tm); err == nil {
+		t.Fatalf("invalid time must fail.")
+	}
+
+	data, _ := json.Marshal(Time{expected})
+
+	if string(data) != `"2024-01-02T03:04:05+0000"` {
+		t.Fatalf("invalid marshaled time. [data:%s]", data)
+	}
+}
+
+func TestFields(t *testing.T) {
+	fields := Fields(FieldID, FieldPicture.With(FieldURL, FieldWidth), FieldComments.Limit(5).With(FieldMessage))
+
+	if fields != "id,picture{url,width},comments.limit(5){message}" {
+		t.Fatalf("invalid fields. [fields:%v]", fields)
+	}
+}
+
+func TestEdges(t *testing.T) {
+	server := fbtest.NewServer()
+	defer server.Close()
+
+	server.Respond("GET", "/200001", loadFixture(t, "page.json"))
+	server.RespondPages("GET", "/200001/posts",
+		[]interface{}{loadFixture(t, "post.json")},
+		[]interface{}{facebook.Result{"id": "200001_300002", "message": "Second"}},
+	)
+	server.Respond("GET", "/200001_300001/reactions", loadFixture(t, "reactions.json"))
+
+	session := server.Session("token")
+	page, err := Get[Page](session, "200001", FieldID, FieldName)
+
+	if err != nil {
+		t.Fatalf("fail to get page. [e:%v]", err)
+	}
+
+	server.LastRequest("GET", "/200001").AssertParams(t, facebook.Params{"fields": "id,name"})
+	since := time.Unix(1700000000, 0)
+	posts, err := page.Posts(session, &EdgeOptions{Limit: 1, Since: since})
+
+	if err != nil {
+		t.Fatalf("fail to read posts. [e:%v]", err)
+	}
+
+	all, err := posts.All()
+
+	if err != nil || len(all) != 2 || all[1].Message != "Second" {
+		t.Fatalf("invalid posts. [posts:%v] [e:%v]", all, err)
+	}
+
+	server.LastRequest("GET", "/200001/posts").AssertParams(t, facebook.Params{
+		"fields": Fields(DefaultPostFields...),
+		"limit":  1,
+		"since":  since.Unix(),
+	})
+
+	reactions, err := all[0].Reactions(session, nil)
+
+	if err != nil {
+		t.Fatalf("fail to read reactions. [e:%v]", err)
+	}
+
+	items, err := reactions.All()
+
+	if err != nil || len(items) != 2 || items[1].Type != ReactionLike {
+		t.Fatalf("invalid reactions. [reactions:%v] [e:%v]", items, err)
+	}
+}
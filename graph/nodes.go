@@ -0,0 +1,205 @@
+// A facebook graph api client in go.
+// https://github.com/huandu/facebook/
+//
+// Copyright 2012, Huan Du
+// Licensed under the MIT license
+// https://github.com/huandu/facebook/blob/master/LICENSE
+
+package graph
+
+// Reaction types.
+const (
+	ReactionLike  = "LIKE"
+	ReactionLove  = "LOVE"
+	ReactionCare  = "CARE"
+	ReactionHaha  = "HAHA"
+	ReactionWow   = "WOW"
+	ReactionSad   = "SAD"
+	ReactionAngry = "ANGRY"
+)
+
+// Profile is the brief information of a user or page, e.g. "from" in a post.
+type Profile struct {
+	ID   string
+	Name string
+}
+
+// Picture is a profile picture.
+type Picture struct {
+	Data PictureData
+}
+
+// PictureData is the data of a profile picture.
+type PictureData struct {
+	URL          string `facebook:"url"`
+	Width        int
+	Height       int
+	IsSilhouette bool
+}
+
+// Image is an image of a photo in a specific size.
+type Image struct {
+	Source string
+	Width  int
+	Height int
+}
+
+// Cover is the cover photo of a page.
+type Cover struct {
+	ID      string
+	Source  string
+	OffsetX float64
+	OffsetY float64
+}
+
+// Place is a location.
+type Place struct {
+	ID       string
+	Name     string
+	Location *Location
+}
+
+// Location is the address and coordinates of a place.
+type Location struct {
+	City      string
+	Country   string
+	State     string
+	Street    string
+	Zip       string
+	Latitude  float64
+	Longitude float64
+}
+
+// User is a Facebook user.
+//
+// Facebook document: https://developers.facebook.com/docs/graph-api/reference/user
+type User struct {
+	ID         string
+	Name       string
+	FirstName  string
+	MiddleName string
+	LastName   string
+	ShortName  string
+	Email      string
+	Birthday   string // in the format of MM/DD/YYYY, MM/DD or YYYY.
+	Link       string
+	Picture    *Picture
+}
+
+// Page is a Facebook page.
+//
+// Facebook document: https://developers.facebook.com/docs/graph-api/reference/page
+type Page struct {
+	ID             string
+	Name           string
+	Category       string
+	About          string
+	Username       string
+	Link           string
+	Website        string
+	FanCount       int64
+	FollowersCount int64
+	Picture        *Picture
+	Cover          *Cover
+	AccessToken    string // page access token. It's returned only if user manages the page.
+}
+
+// Post is a post in a feed.
+//
+// Facebook document: https://developers.facebook.com/docs/graph-api/reference/post
+type Post struct {
+	ID           string
+	Message      string
+	Story        string
+	CreatedTime  Time
+	UpdatedTime  Time
+	PermalinkURL string `facebook:"permalink_url"`
+	From         *Profile
+	FullPicture  string
+	StatusType   string
+	Shares       *struct {
+		Count int64
+	}
+}
+
+// Photo is a photo.
+//
+// Facebook document: https://developers.facebook.com/docs/graph-api/reference/photo
+type Photo struct {
+	ID          string
+	Name        string // caption of the photo.
+	Link        string
+	CreatedTime Time
+	From        *Profile
+	Album       *Album
+	Images      []Image
+	Width       int
+	Height      int
+}
+
+// Video is a video.
+//
+// Facebook document: https://developers.facebook.com/docs/graph-api/reference/video
+type Video struct {
+	ID           string
+	Title        string
+	Description  string
+	Source       string
+	PermalinkURL string  `facebook:"permalink_url"`
+	Length       float64 // length in seconds.
+	CreatedTime  Time
+	UpdatedTime  Time
+	From         *Profile
+}
+
+// Comment is a comment on an object.
+//
+// Facebook document: https://developers.facebook.com/docs/graph-api/reference/comment
+type Comment struct {
+	ID           string
+	Message      string
+	CreatedTime  Time
+	From         *Profile
+	LikeCount    int64
+	CommentCount int64
+	PermalinkURL string   `facebook:"permalink_url"`
+	Parent       *Comment // parent comment of a reply.
+}
+
+// Album is a photo album.
+//
+// Facebook document: https://developers.facebook.com/docs/graph-api/reference/album
+type Album struct {
+	ID          string
+	Name        string
+	Description string
+	Count       int64 // number of photos.
+	CreatedTime Time
+	Link        string
+	CoverPhoto  *Photo
+}
+
+// Event is an event.
+//
+// Facebook document: https://developers.facebook.com/docs/graph-api/reference/event
+type Event struct {
+	ID              string
+	Name            string
+	Description     string
+	StartTime       Time
+	EndTime         Time
+	Timezone        string
+	Place           *Place
+	AttendingCount  int64
+	InterestedCount int64
+	IsOnline        bool
+}
+
+// Reaction is a reaction to an object.
+//
+// Facebook document: https://developers.facebook.com/docs/graph-api/reference/object/reactions
+type Reaction struct {
+	ID   string // id of the user or page.
+	Name string
+	Type string // one of Reaction* constants.
+}
@@ -0,0 +1,378 @@
+// A facebook graph api client in go.
+// https://github.com/huandu/facebook/
+//
+// Copyright 2012, Huan Du
+// Licensed under the MIT license
+// https://github.com/huandu/facebook/blob/master/LICENSE
+
+package facebook
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limited Login id_token constants.
+const (
+	DefaultJWKSURL    = "https://limited.facebook.com/.well-known/oauth/openid/jwks/"
+	DefaultKeySetTTL  = 24 * time.Hour
+	IDTokenIssuer     = "https://www.facebook.com"
+	idTokenAlgorithm  = "RS256"
+	keySetMinInterval = time.Minute // min interval to refetch JWKS for an unknown key id.
+)
+
+// KeySet provides public keys to verify id_token signatures.
+type KeySet interface {
+	// PublicKey returns the public key by key id, the "kid" in JWT header.
+	PublicKey(kid string) (*rsa.PublicKey, error)
+}
+
+// StaticKeySet is a fixed set of public keys indexed by key id.
+// It's useful to verify id_token offline or in tests.
+type StaticKeySet map[string]*rsa.PublicKey
+
+var _ KeySet = StaticKeySet{}
+
+// ParseJWKS parses a JSON Web Key Set document. Only RSA keys are parsed.
+func ParseJWKS(data []byte) (StaticKeySet, error) {
+	var jwks struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+
+	if err := json.Unmarshal(data, &jwks); err != nil {
+		return nil, fmt.Errorf("facebook: fail to parse JWKS; %w", err)
+	}
+
+	keys := StaticKeySet{}
+
+	for _, key := range jwks.Keys {
+		if key.Kty != "RSA" || (key.Use != "" && key.Use != "sig") {
+			continue
+		}
+
+		n, err := base64.RawURLEncoding.DecodeString(key.N)
+
+		if err != nil {
+			return nil, fmt.Errorf("facebook: invalid modulus of key '%v' in JWKS; %w", key.Kid, err)
+		}
+
+		e, err := base64.RawURLEncoding.DecodeString(key.E)
+
+		if err != nil {
+			return nil, fmt.Errorf("facebook: invalid exponent of key '%v' in JWKS; %w", key.Kid, err)
+		}
+
+		exp := new(big.Int).SetBytes(e)
+
+		if !exp.IsInt64() || exp.Int64() <= 1 || exp.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("facebook: invalid exponent of key '%v' in JWKS", key.Kid)
+		}
+
+		keys[key.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(exp.Int64()),
+		}
+	}
+
+	return keys, nil
+}
+
+// PublicKey returns the public key by key id.
+func (ks StaticKeySet) PublicKey(kid string) (*rsa.PublicKey, error) {
+	key, ok := ks[kid]
+
+	if !ok {
+		return nil, fmt.Errorf("facebook: unknown id_token key id '%v'", kid)
+	}
+
+	return key, nil
+}
+
+// RemoteKeySet fetches JWKS from URL and caches keys for TTL.
+// If a key id is not found in cache, JWKS is fetched again at most once per minute.
+type RemoteKeySet struct {
+	URL string        // JWKS url. DefaultJWKSURL is used if it's empty.
+	TTL time.Duration // cache duration. DefaultKeySetTTL is used if it's 0.
+
+	session   *Session
+	mu        sync.Mutex
+	keys      StaticKeySet
+	fetchedAt time.Time
+}
+
+var _ KeySet = &RemoteKeySet{}
+
+// NewRemoteKeySet creates a RemoteKeySet fetching JWKS through session's HttpClient.
+// If session is nil, default session is used.
+func NewRemoteKeySet(session *Session, url string) *RemoteKeySet {
+	if session == nil {
+		session = defaultSession
+	}
+
+	return &RemoteKeySet{
+		URL:     url,
+		session: session,
+	}
+}
+
+// PublicKey returns the public key by key id.
+func (ks *RemoteKeySet) PublicKey(kid string) (*rsa.PublicKey, error) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	ttl := ks.TTL
+
+	if ttl == 0 {
+		ttl = DefaultKeySetTTL
+	}
+
+	since := time.Since(ks.fetchedAt)
+
+	if ks.keys != nil && since < ttl {
+		if key, ok := ks.keys[kid]; ok || since < keySetMinInterval {
+			if !ok {
+				return nil, fmt.Errorf("facebook: unknown id_token key id '%v'", kid)
+			}
+
+			return key, nil
+		}
+	}
+
+	keys, err := ks.fetch()
+
+	if err != nil {
+		return nil, err
+	}
+
+	ks.keys = keys
+	ks.fetchedAt = time.Now()
+	return keys.PublicKey(kid)
+}
+
+func (ks *RemoteKeySet) fetch() (StaticKeySet, error) {
+	url := ks.URL
+
+	if url == "" {
+		url = DefaultJWKSURL
+	}
+
+	request, err := http.NewRequest("GET", url, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// use a bare session so that access token is never sent to JWKS url.
+	session := &Session{
+		HttpClient: ks.session.HttpClient,
+		context:    ks.session.context,
+	}
+	response, data, err := session.sendRequest(request, credential{})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("facebook: fail to fetch JWKS with status %v", response.StatusCode)
+	}
+
+	return ParseJWKS(data)
+}
+
+// IDTokenClaims is the typed claims of a Limited Login id_token.
+//
+// Facebook document: https://developers.facebook.com/docs/facebook-login/limited-login/token
+type IDTokenClaims struct {
+	Issuer     string    // "iss".
+	Audience   string    // "aud". It's the app id.
+	Subject    string    // "sub". It's the app-scoped user id.
+	IssuedAt   time.Time // "iat".
+	ExpiresAt  time.Time // "exp".
+	JWTID      string    // "jti".
+	Nonce      string
+	Name       string
+	GivenName  string
+	MiddleName string
+	FamilyName string
+	Email      string
+	Picture    string
+
+	Raw Result // all claims in the id_token.
+}
+
+type idTokenData struct {
+	Issuer     string `facebook:"iss"`
+	Subject    string `facebook:"sub"`
+	IssuedAt   Int64  `facebook:"iat"`
+	ExpiresAt  Int64  `facebook:"exp"`
+	JWTID      string `facebook:"jti"`
+	Nonce      string
+	Name       string
+	GivenName  string
+	MiddleName string
+	FamilyName string
+	Email      string
+	Picture    string
+}
+
+// idTokenKeySetMu guards App.remoteKeySet.
+var idTokenKeySetMu sync.Mutex
+
+// VerifyIDToken verifies a Limited Login id_token and returns its claims.
+//
+// The RS256 signature is checked against app.IDTokenKeySet. If it's not set,
+// keys are fetched from DefaultJWKSURL through the HttpClient of app's session and cached.
+// The iss must be IDTokenIssuer, aud must be app.AppId and the token must not expire.
+// If nonce is not empty, it must equal to the nonce claim.
+func (app *App) VerifyIDToken(token, nonce string) (*IDTokenClaims, error) {
+	parts := strings.Split(token, ".")
+
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("facebook: invalid id_token format")
+	}
+
+	headerBytes, err := base64.RawURLEncoding.DecodeString(parts[0])
+
+	if err != nil {
+		return nil, fmt.Errorf("facebook: fail to decode id_token header; %w", err)
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+
+	if err = json.Unmarshal(headerBytes, &header); err != nil {
+		return nil, fmt.Errorf("facebook: fail to parse id_token header; %w", err)
+	}
+
+	if header.Alg != idTokenAlgorithm {
+		return nil, fmt.Errorf("facebook: id_token algorithm must be '%v'; actual is '%v'", idTokenAlgorithm, header.Alg)
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+
+	if err != nil {
+		return nil, fmt.Errorf("facebook: fail to decode id_token signature; %w", err)
+	}
+
+	key, err := app.idTokenKeySet().PublicKey(header.Kid)
+
+	if err != nil {
+		return nil, err
+	}
+
+	hash := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+
+	if err = rsa.VerifyPKCS1v15(key, crypto.SHA256, hash[:], sig); err != nil {
+		return nil, fmt.Errorf("facebook: bad id_token signature")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+
+	if err != nil {
+		return nil, fmt.Errorf("facebook: fail to decode id_token payload; %w", err)
+	}
+
+	res, err := MakeResult(payload)
+
+	if err != nil {
+		return nil, fmt.Errorf("facebook: fail to parse id_token payload; %w", err)
+	}
+
+	var data idTokenData
+
+	if err = res.Decode(&data); err != nil {
+		return nil, fmt.Errorf("facebook: fail to decode id_token claims; %w", err)
+	}
+
+	claims := &IDTokenClaims{
+		Issuer:     data.Issuer,
+		Subject:    data.Subject,
+		IssuedAt:   unixTime(data.IssuedAt),
+		ExpiresAt:  unixTime(data.ExpiresAt),
+		JWTID:      data.JWTID,
+		Nonce:      data.Nonce,
+		Name:       data.Name,
+		GivenName:  data.GivenName,
+		MiddleName: data.MiddleName,
+		FamilyName: data.FamilyName,
+		Email:      data.Email,
+		Picture:    data.Picture,
+		Raw:        res,
+	}
+
+	if err = app.validateIDTokenClaims(claims); err != nil {
+		return nil, err
+	}
+
+	if nonce != "" && claims.Nonce != nonce {
+		return nil, fmt.Errorf("facebook: id_token nonce mismatch")
+	}
+
+	return claims, nil
+}
+
+func (app *App) validateIDTokenClaims(claims *IDTokenClaims) error {
+	if claims.Issuer != IDTokenIssuer {
+		return fmt.Errorf("facebook: invalid id_token issuer '%v'", claims.Issuer)
+	}
+
+	// aud can be a string or an array of strings in JWT.
+	switch aud := claims.Raw["aud"].(type) {
+	case string:
+		claims.Audience = aud
+	case []interface{}:
+		for _, v := range aud {
+			if s, ok := v.(string); ok && s == app.AppId {
+				claims.Audience = s
+			}
+		}
+	}
+
+	if claims.Audience == "" || claims.Audience != app.AppId {
+		return fmt.Errorf("facebook: id_token is not issued for app '%v'", app.AppId)
+	}
+
+	if claims.ExpiresAt.IsZero() {
+		return fmt.Errorf("facebook: exp is missing in id_token")
+	}
+
+	if time.Now().After(claims.ExpiresAt.Add(app.IDTokenClockSkew)) {
+		return fmt.Errorf("facebook: id_token is expired at %v", claims.ExpiresAt)
+	}
+
+	return nil
+}
+
+func (app *App) idTokenKeySet() KeySet {
+	if app.IDTokenKeySet != nil {
+		return app.IDTokenKeySet
+	}
+
+	session := app.getSession()
+	idTokenKeySetMu.Lock()
+	defer idTokenKeySetMu.Unlock()
+
+	if app.remoteKeySet == nil || app.remoteKeySet.session != session {
+		app.remoteKeySet = NewRemoteKeySet(session, "")
+	}
+
+	return app.remoteKeySet
+}
@@ -0,0 +1,165 @@
+// A facebook graph api client in go.
+// https://github.com/huandu/facebook/
+//
+// Copyright 2012, Huan Du
+// Licensed under the MIT license
+// https://github.com/huandu/facebook/blob/master/LICENSE
+
+package facebook
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func testSignIDToken(t *testing.T, key *rsa.PrivateKey, kid string, claims Result) string {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": kid})
+	payload, _ := json.Marshal(claims)
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	hash := sha256.Sum256([]byte(signingInput))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hash[:])
+
+	if err != nil {
+		t.Fatalf("fail to sign id_token. [e:%v]", err)
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func testJWKS(kid string, key *rsa.PublicKey) string {
+	return fmt.Sprintf(`{"keys":[{"kty":"RSA","use":"sig","alg":"RS256","kid":"%v","n":"%v","e":"%v"}]}`,
+		kid,
+		base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()))
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+
+	if err != nil {
+		t.Fatalf("fail to generate key. [e:%v]", err)
+	}
+
+	keys, err := ParseJWKS([]byte(testJWKS("kid1", &key.PublicKey)))
+
+	if err != nil {
+		t.Fatalf("fail to parse JWKS. [e:%v]", err)
+	}
+
+	app := New("123", "secret")
+	app.IDTokenKeySet = keys
+	now := time.Now().Unix()
+	claims := func(overrides Result) Result {
+		c := Result{
+			"iss":   IDTokenIssuer,
+			"aud":   "123",
+			"sub":   "456",
+			"iat":   now,
+			"exp":   now + 3600,
+			"jti":   "jti",
+			"nonce": "nonce",
+			"name":  "Jane Doe",
+			"email": "jane@example.com",
+		}
+
+		for k, v := range overrides {
+			c[k] = v
+		}
+
+		return c
+	}
+
+	token := testSignIDToken(t, key, "kid1", claims(nil))
+	c, err := app.VerifyIDToken(token, "nonce")
+
+	if err != nil {
+		t.Fatalf("fail to verify id_token. [e:%v]", err)
+	}
+
+	if c.Subject != "456" || c.Audience != "123" || c.Name != "Jane Doe" || c.Email != "jane@example.com" || c.ExpiresAt.Unix() != now+3600 {
+		t.Fatalf("invalid claims. [claims:%#v]", c)
+	}
+
+	cases := map[string]string{
+		"wrong nonce":    token,
+		"wrong issuer":   testSignIDToken(t, key, "kid1", claims(Result{"iss": "https://example.com"})),
+		"wrong audience": testSignIDToken(t, key, "kid1", claims(Result{"aud": "789"})),
+		"expired":        testSignIDToken(t, key, "kid1", claims(Result{"exp": now - 60})),
+		"unknown kid":    testSignIDToken(t, key, "kid2", claims(nil)),
+		"bad signature":  token[:len(token)-4] + "AAAA",
+		"bad format":     "abc.def",
+	}
+
+	for name, token := range cases {
+		nonce := "nonce"
+
+		if name == "wrong nonce" {
+			nonce = "other"
+		}
+
+		if _, err := app.VerifyIDToken(token, nonce); err == nil {
+			t.Fatalf("id_token must be rejected. [case:%v]", name)
+		}
+	}
+
+	app.IDTokenClockSkew = 5 * time.Minute
+
+	if _, err := app.VerifyIDToken(cases["expired"], ""); err != nil {
+		t.Fatalf("id_token must be accepted with clock skew. [e:%v]", err)
+	}
+}
+
+func TestRemoteKeySet(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+
+	if err != nil {
+		t.Fatalf("fail to generate key. [e:%v]", err)
+	}
+
+	fetched := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetched++
+
+		if r.Header.Get("Authorization") != "" || r.URL.Query().Get("access_token") != "" {
+			t.Errorf("access token must not be sent to JWKS url.")
+		}
+
+		w.Write([]byte(testJWKS("kid1", &key.PublicKey)))
+	}))
+	defer server.Close()
+
+	app := New("123", "secret")
+	session := app.Session("token")
+	session.UseAuthorizationHeader()
+	app.IDTokenKeySet = NewRemoteKeySet(session, server.URL)
+	token := testSignIDToken(t, key, "kid1", Result{
+		"iss": IDTokenIssuer,
+		"aud": "123",
+		"sub": "456",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+
+	for i := 0; i < 3; i++ {
+		if _, err := app.VerifyIDToken(token, ""); err != nil {
+			t.Fatalf("fail to verify id_token. [e:%v]", err)
+		}
+	}
+
+	if _, err := app.VerifyIDToken(testSignIDToken(t, key, "kid2", Result{}), ""); err == nil {
+		t.Fatalf("unknown kid must be rejected.")
+	}
+
+	if fetched != 1 {
+		t.Fatalf("JWKS must be fetched once. [fetched:%v]", fetched)
+	}
+}
@@ -0,0 +1,363 @@
+// A facebook graph api client in go.
+// https://github.com/huandu/facebook/
+//
+// Copyright 2012, Huan Du
+// Licensed under the MIT license
+// https://github.com/huandu/facebook/blob/master/LICENSE
+
+package facebook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Usage gauge scopes.
+const (
+	UsageScopeApp             = "app"
+	UsageScopePage            = "page"
+	UsageScopeAdAccount       = "ad_account"
+	UsageScopeAdsInsights     = "ads_insights"
+	UsageScopeBusinessUseCase = "business_use_case"
+)
+
+var (
+	// matches node ids like "123" or "123_456" in path.
+	regexpNodeID = regexp.MustCompile(`^\d+(_\d+)*$`)
+
+	// matches version prefix like "v19.0" in path.
+	regexpVersionSegment = regexp.MustCompile(`^v\d+\.\d+$`)
+)
+
+// Instrumenter receives start and end events of every http request sent by Session
+// and every operation in a batch request.
+// It's designed to be dependency-free so that adapters for metrics and tracing
+// libraries can live outside this package.
+//
+// Implementations must be safe for concurrent use.
+type Instrumenter interface {
+	// StartCall is called before a request is sent.
+	// The returned context is used to send the request. It can carry a tracing span.
+	StartCall(ctx context.Context, call *CallInfo) context.Context
+
+	// EndCall is called with the context returned by StartCall when a request is done.
+	EndCall(ctx context.Context, call *CallInfo, result *CallResult)
+}
+
+// CallInfo describes a Graph API call or a batch operation.
+type CallInfo struct {
+	Method     string // http method.
+	Path       string // url path without version, e.g. "/123/feed".
+	Endpoint   string // endpoint template without ids, e.g. "/{id}/feed". Use it as a low cardinality label.
+	AppID      string // id of the App associated with Session. It can be empty.
+	ObjectID   string // id of the first node in path, e.g. "123" or "act_123". It can be empty.
+	Batch      bool   // whether it's an operation in a batch request.
+	BatchIndex int    // index of the operation in batch request.
+}
+
+// CallResult is the result of a Graph API call or a batch operation.
+//
+// Operations in a batch request are started before the batch request is sent
+// and ended after the batch response is received or the batch request fails.
+// Their Duration is the duration of the whole batch request and
+// Err is the error of the batch request, if any.
+type CallResult struct {
+	StatusCode   int           // http status code. It's 0 if server is not reachable.
+	ErrorCode    int           // graph api error code in response.
+	ErrorSubcode int           // graph api error subcode in response.
+	Retries      int           // number of retries. Session doesn't retry requests by itself, so it's 0 for now.
+	BytesOut     int64         // size of request body. It's -1 if size is unknown.
+	BytesIn      int64         // size of response body.
+	Duration     time.Duration // duration of the call.
+	Usage        *UsageInfo    // usage information in response headers. It's nil if there is no usage header.
+	Gauges       []UsageGauge  // usage percentages in response headers.
+	Err          error         // redacted transport error.
+}
+
+// UsageGauge is a usage percentage reported by facebook in response headers.
+type UsageGauge struct {
+	Scope string  // one of UsageScopeApp, UsageScopePage, UsageScopeAdAccount, UsageScopeAdsInsights or UsageScopeBusinessUseCase.
+	ID    string  // app id, page id, ad account id or business id. It can be empty if it's unknown.
+	Type  string  // rate limit type in business use case usage, e.g. "pages" or "ads_management".
+	Name  string  // metric name, e.g. "call_count", "total_time", "total_cputime" or "acc_id_util_pct".
+	Value float64 // percentage.
+}
+
+// SetInstrumenter sets an instrumenter. Set instrumenter to nil to stop instrumentation.
+func (session *Session) SetInstrumenter(instrumenter Instrumenter) {
+	session.instrumenter = instrumenter
+}
+
+// Instrumenter returns current instrumenter. It can be nil.
+func (session *Session) Instrumenter() Instrumenter {
+	return session.instrumenter
+}
+
+// EndpointTemplate removes version and replaces ids in path with placeholders.
+// For instance, "/v19.0/123_456/comments" is converted to "/{id}/comments"
+// and "/act_123/insights" is converted to "/act_{id}/insights".
+func EndpointTemplate(path string) string {
+	segments := splitPath(path)
+
+	for i, segment := range segments {
+		if regexpNodeID.MatchString(segment) {
+			segments[i] = "{id}"
+		} else if strings.HasPrefix(segment, "act_") && regexpNodeID.MatchString(segment[4:]) {
+			segments[i] = "act_{id}"
+		}
+	}
+
+	return "/" + strings.Join(segments, "/")
+}
+
+// splitPath splits path into segments without version.
+func splitPath(path string) []string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	if len(segments) > 0 && regexpVersionSegment.MatchString(segments[0]) {
+		segments = segments[1:]
+	}
+
+	if len(segments) == 1 && segments[0] == "" {
+		return nil
+	}
+
+	return segments
+}
+
+func (session *Session) newCallInfo(method, path string) *CallInfo {
+	segments := splitPath(path)
+	call := &CallInfo{
+		Method:   method,
+		Path:     "/" + strings.Join(segments, "/"),
+		Endpoint: EndpointTemplate(path),
+	}
+
+	if session.app != nil {
+		call.AppID = session.app.AppId
+	}
+
+	if len(segments) > 0 && EndpointTemplate(segments[0]) != "/"+segments[0] {
+		call.ObjectID = segments[0]
+	}
+
+	return call
+}
+
+// startCall notifies instrumenter that request is about to be sent.
+func (session *Session) startCall(request *http.Request) (*http.Request, *CallInfo) {
+	call := session.newCallInfo(request.Method, request.URL.Path)
+	ctx := session.instrumenter.StartCall(request.Context(), call)
+
+	if ctx != nil && ctx != request.Context() {
+		request = request.WithContext(ctx)
+	}
+
+	return request, call
+}
+
+// endCall notifies instrumenter that request is done.
+func (session *Session) endCall(request *http.Request, call *CallInfo, start time.Time, response *http.Response, data []byte, err error) {
+	result := &CallResult{
+		BytesOut: request.ContentLength,
+		BytesIn:  int64(len(data)),
+		Duration: time.Since(start),
+		Err:      RedactError(err),
+	}
+
+	if request.Body == nil || request.Body == http.NoBody {
+		result.BytesOut = 0
+	}
+
+	if response != nil {
+		result.StatusCode = response.StatusCode
+		result.Usage = usageFromResponse(session, response)
+		result.Gauges = usageGauges(response.Header, call)
+		result.ErrorCode, result.ErrorSubcode, _ = parseErrorCode(data)
+	}
+
+	session.instrumenter.EndCall(request.Context(), call, result)
+}
+
+// batchCall is an operation in a batch request reported to instrumenter.
+type batchCall struct {
+	ctx  context.Context
+	call *CallInfo
+}
+
+// startBatch notifies instrumenter that every operation in a batch request starts.
+func (session *Session) startBatch(ctx context.Context, params []Params) []batchCall {
+	calls := make([]batchCall, 0, len(params))
+
+	for i, op := range params {
+		method := "GET"
+
+		if m, ok := op["method"]; ok {
+			method = strings.ToUpper(fmt.Sprint(m))
+		}
+
+		relativeURL := fmt.Sprint(op["relative_url"])
+
+		if u, err := url.Parse(relativeURL); err == nil {
+			relativeURL = u.Path
+		}
+
+		call := session.newCallInfo(method, relativeURL)
+		call.Batch = true
+		call.BatchIndex = i
+		opCtx := session.instrumenter.StartCall(ctx, call)
+
+		if opCtx == nil {
+			opCtx = ctx
+		}
+
+		calls = append(calls, batchCall{
+			ctx:  opCtx,
+			call: call,
+		})
+	}
+
+	return calls
+}
+
+// endBatch notifies instrumenter that every operation in a batch request is done.
+// If the batch request fails, err is reported for every operation.
+func (session *Session) endBatch(calls []batchCall, start time.Time, results []Result, err error) {
+	duration := time.Since(start)
+	err = RedactError(err)
+
+	for i, bc := range calls {
+		result := &CallResult{
+			BytesOut: -1,
+			Duration: duration,
+			Err:      err,
+		}
+
+		if err == nil && i < len(results) && results[i] != nil {
+			if br, e := results[i].Batch(); e == nil {
+				result.StatusCode = br.StatusCode
+				result.BytesIn = int64(len(br.Body))
+				result.ErrorCode, result.ErrorSubcode, _ = parseErrorCode([]byte(br.Body))
+				result.Gauges = usageGauges(br.Header, bc.call)
+			}
+		}
+
+		session.instrumenter.EndCall(bc.ctx, bc.call, result)
+	}
+}
+
+// parseErrorCode parses graph api error code in response body.
+func parseErrorCode(data []byte) (code, subcode int, traceID string) {
+	if len(data) == 0 || !strings.Contains(string(data), `"error"`) {
+		return
+	}
+
+	var body struct {
+		Error *struct {
+			Code         int    `json:"code"`
+			ErrorSubcode int    `json:"error_subcode"`
+			TraceID      string `json:"fbtrace_id"`
+		} `json:"error"`
+	}
+
+	if json.Unmarshal(data, &body) != nil || body.Error == nil {
+		return
+	}
+
+	return body.Error.Code, body.Error.ErrorSubcode, body.Error.TraceID
+}
+
+// usageFromResponse returns usage information in response headers or nil if there is no usage header.
+func usageFromResponse(session *Session, response *http.Response) *UsageInfo {
+	for _, name := range usageHeaders {
+		if response.Header.Get(name) != "" {
+			return session.addUsageInfo(Result{}, response).UsageInfo()
+		}
+	}
+
+	return nil
+}
+
+var usageHeaders = []string{"X-App-Usage", "X-Page-Usage", "X-Ad-Account-Usage", "X-Business-Use-Case-Usage", "X-Fb-Ads-Insights-Throttle"}
+
+// usageGauges converts usage headers to gauges.
+func usageGauges(header http.Header, call *CallInfo) (gauges []UsageGauge) {
+	add := func(scope, id, typ, name string, value float64) {
+		gauges = append(gauges, UsageGauge{
+			Scope: scope,
+			ID:    id,
+			Type:  typ,
+			Name:  name,
+			Value: value,
+		})
+	}
+	addRateLimiting := func(scope, id string, rl *RateLimiting) {
+		add(scope, id, rl.Type, "call_count", float64(rl.CallCount))
+		add(scope, id, rl.Type, "total_time", float64(rl.TotalTime))
+		add(scope, id, rl.Type, "total_cputime", float64(rl.TotalCPUTime))
+	}
+
+	if usage := header.Get("X-App-Usage"); usage != "" {
+		var rl RateLimiting
+
+		if json.Unmarshal([]byte(usage), &rl) == nil {
+			addRateLimiting(UsageScopeApp, call.AppID, &rl)
+		}
+	}
+
+	if usage := header.Get("X-Page-Usage"); usage != "" {
+		var rl RateLimiting
+
+		if json.Unmarshal([]byte(usage), &rl) == nil {
+			addRateLimiting(UsageScopePage, call.ObjectID, &rl)
+		}
+	}
+
+	if usage := header.Get("X-Ad-Account-Usage"); usage != "" {
+		var aa AdAccountUsage
+
+		if json.Unmarshal([]byte(usage), &aa) == nil {
+			add(UsageScopeAdAccount, call.ObjectID, "", "acc_id_util_pct", aa.AccIDUtilPCT)
+		}
+	}
+
+	if usage := header.Get("X-Fb-Ads-Insights-Throttle"); usage != "" {
+		var ai AdsInsightsThrottle
+
+		if json.Unmarshal([]byte(usage), &ai) == nil {
+			add(UsageScopeAdsInsights, call.ObjectID, "", "app_id_util_pct", ai.AppIDUtilPCT)
+			add(UsageScopeAdsInsights, call.ObjectID, "", "acc_id_util_pct", ai.AccIDUtilPCT)
+		}
+	}
+
+	if usage := header.Get("X-Business-Use-Case-Usage"); usage != "" {
+		var buc BusinessUseCaseUsage
+
+		if json.Unmarshal([]byte(usage), &buc) == nil {
+			ids := make([]string, 0, len(buc))
+
+			for id := range buc {
+				ids = append(ids, id)
+			}
+
+			sort.Strings(ids)
+
+			for _, id := range ids {
+				for _, rl := range buc[id] {
+					if rl != nil {
+						addRateLimiting(UsageScopeBusinessUseCase, id, rl)
+					}
+				}
+			}
+		}
+	}
+
+	return
+}
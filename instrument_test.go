@@ -0,0 +1,187 @@
+// A facebook graph api client in go.
+// https://github.com/huandu/facebook/
+//
+// Copyright 2012, Huan Du
+// Licensed under the MIT license
+// https://github.com/huandu/facebook/blob/master/LICENSE
+
+package facebook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+type testInstrumenter struct {
+	mu      sync.Mutex
+	started []*CallInfo
+	ended   []*CallResult
+}
+
+type testInstrumenterKey struct{}
+
+func (inst *testInstrumenter) StartCall(ctx context.Context, call *CallInfo) context.Context {
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	inst.started = append(inst.started, call)
+	return context.WithValue(ctx, testInstrumenterKey{}, call)
+}
+
+func (inst *testInstrumenter) EndCall(ctx context.Context, call *CallInfo, result *CallResult) {
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	if ctx.Value(testInstrumenterKey{}) != call {
+		panic("context returned by StartCall must be passed to EndCall")
+	}
+
+	inst.ended = append(inst.ended, result)
+}
+
+func TestEndpointTemplate(t *testing.T) {
+	cases := map[string]string{
+		"/v19.0/123_456/comments": "/{id}/comments",
+		"/act_123/insights":       "/act_{id}/insights",
+		"me/accounts":             "/me/accounts",
+		"/v19.0/":                 "/",
+		"/app/subscriptions":      "/app/subscriptions",
+	}
+
+	for path, expected := range cases {
+		if actual := EndpointTemplate(path); actual != expected {
+			t.Fatalf("invalid endpoint template. [path:%v] [expected:%v] [actual:%v]", path, expected, actual)
+		}
+	}
+}
+
+func TestSessionInstrumenter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-App-Usage", `{"call_count":12,"total_time":3,"total_cputime":4}`)
+		w.Header().Set("X-Business-Use-Case-Usage", `{"789":[{"type":"pages","call_count":5,"total_time":6,"total_cputime":7}]}`)
+
+		switch r.URL.Path {
+		case "/v19.0/123/feed":
+			fmt.Fprint(w, `{"id":"123_1"}`)
+		case "/v19.0/":
+			body, _ := json.Marshal([]Result{
+				{"code": 200, "body": `{"id":"123"}`, "headers": []Result{{"name": "X-Page-Usage", "value": `{"call_count":9}`}}},
+				{"code": 400, "body": `{"error":{"message":"bad","code":100,"error_subcode":33}}`},
+			})
+			w.Write(body)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":{"message":"invalid token","code":190,"error_subcode":463}}`)
+		}
+	}))
+	defer server.Close()
+
+	inst := &testInstrumenter{}
+	session := New("app", "secret").Session("token")
+	session.BaseURL = server.URL + "/"
+	session.Version = "v19.0"
+	session.SetInstrumenter(inst)
+
+	if _, err := session.Post("/123/feed", Params{"message": "hello"}); err != nil {
+		t.Fatalf("fail to post. [e:%v]", err)
+	}
+
+	session.Get("/me", nil)
+
+	if len(inst.started) != 2 || len(inst.ended) != 2 {
+		t.Fatalf("every call must be instrumented. [started:%v] [ended:%v]", len(inst.started), len(inst.ended))
+	}
+
+	call, result := inst.started[0], inst.ended[0]
+
+	if call.Method != "POST" || call.Path != "/123/feed" || call.Endpoint != "/{id}/feed" || call.AppID != "app" || call.ObjectID != "123" {
+		t.Fatalf("invalid call info. [call:%#v]", call)
+	}
+
+	if result.StatusCode != 200 || result.BytesOut <= 0 || result.BytesIn != int64(len(`{"id":"123_1"}`)) || result.Usage == nil || result.Usage.App.CallCount != 12 {
+		t.Fatalf("invalid call result. [result:%#v]", result)
+	}
+
+	expectedGauges := []UsageGauge{
+		{Scope: UsageScopeApp, ID: "app", Name: "call_count", Value: 12},
+		{Scope: UsageScopeApp, ID: "app", Name: "total_time", Value: 3},
+		{Scope: UsageScopeApp, ID: "app", Name: "total_cputime", Value: 4},
+		{Scope: UsageScopeBusinessUseCase, ID: "789", Type: "pages", Name: "call_count", Value: 5},
+		{Scope: UsageScopeBusinessUseCase, ID: "789", Type: "pages", Name: "total_time", Value: 6},
+		{Scope: UsageScopeBusinessUseCase, ID: "789", Type: "pages", Name: "total_cputime", Value: 7},
+	}
+
+	if fmt.Sprint(result.Gauges) != fmt.Sprint(expectedGauges) {
+		t.Fatalf("invalid gauges. [gauges:%v]", result.Gauges)
+	}
+
+	if result := inst.ended[1]; result.StatusCode != 400 || result.ErrorCode != 190 || result.ErrorSubcode != 463 {
+		t.Fatalf("invalid error call result. [result:%#v]", result)
+	}
+
+	inst.started, inst.ended = nil, nil
+	_, err := session.BatchApi(
+		Params{"method": GET, "relative_url": "123?fields=id"},
+		Params{"method": "post", "relative_url": "act_456/ads"},
+	)
+
+	if err != nil {
+		t.Fatalf("fail to send batch. [e:%v]", err)
+	}
+
+	if len(inst.started) != 3 || len(inst.ended) != 3 {
+		t.Fatalf("batch and its operations must be instrumented. [started:%v] [ended:%v]", len(inst.started), len(inst.ended))
+	}
+
+	// operations must be started before sending the batch request.
+	if !inst.started[0].Batch || !inst.started[1].Batch || inst.started[2].Batch {
+		t.Fatalf("batch operations must be started before batch request.")
+	}
+
+	op := inst.started[0]
+
+	if !op.Batch || op.BatchIndex != 0 || op.Method != "GET" || op.Endpoint != "/{id}" || op.ObjectID != "123" {
+		t.Fatalf("invalid batch operation. [op:%#v]", op)
+	}
+
+	if result := inst.ended[1]; result.StatusCode != 200 || len(result.Gauges) != 3 || result.Gauges[0].Scope != UsageScopePage || result.Gauges[0].ID != "123" {
+		t.Fatalf("invalid batch operation result. [result:%#v]", result)
+	}
+
+	op = inst.started[1]
+
+	if op.Method != "POST" || op.Endpoint != "/act_{id}/ads" || op.ObjectID != "act_456" {
+		t.Fatalf("invalid batch operation. [op:%#v]", op)
+	}
+
+	if result := inst.ended[2]; result.StatusCode != 400 || result.ErrorCode != 100 || result.ErrorSubcode != 33 {
+		t.Fatalf("invalid batch operation result. [result:%#v]", result)
+	}
+
+	// every operation must be ended with the error of a failed batch request.
+	inst.started, inst.ended = nil, nil
+	server.Close()
+	_, err = session.BatchApi(
+		Params{"method": GET, "relative_url": "123"},
+		Params{"method": GET, "relative_url": "456"},
+	)
+
+	if err == nil {
+		t.Fatalf("batch request must fail.")
+	}
+
+	if len(inst.started) != 3 || len(inst.ended) != 3 {
+		t.Fatalf("failed batch and its operations must be instrumented. [started:%v] [ended:%v]", len(inst.started), len(inst.ended))
+	}
+
+	for _, result := range inst.ended {
+		if result.Err == nil {
+			t.Fatalf("batch error must be reported. [result:%#v]", result)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+// A facebook graph api client in go.
+// https://github.com/huandu/facebook/
+//
+// Copyright 2012, Huan Du
+// Licensed under the MIT license
+// https://github.com/huandu/facebook/blob/master/LICENSE
+
+package facebook
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"time"
+)
+
+// max size of a request body in LogEntry.
+const maxLogBodySize = 4096
+
+// Logger logs every http request sent by Session.
+// Implementations must be safe for concurrent use.
+type Logger interface {
+	LogCall(entry *LogEntry)
+}
+
+// LoggerFunc is an adapter to use a func as a Logger.
+type LoggerFunc func(entry *LogEntry)
+
+// LogCall calls f(entry).
+func (f LoggerFunc) LogCall(entry *LogEntry) {
+	f(entry)
+}
+
+// LogEntry is a logged http request.
+// All secrets in URL, RequestBody and Err are redacted by Redact.
+type LogEntry struct {
+	Method       string        // http method.
+	Path         string        // url path, e.g. "/v19.0/me".
+	URL          string        // redacted url.
+	RequestBody  string        // redacted url-encoded request body. It's empty for other bodies.
+	Duration     time.Duration // duration of the request including reading response body.
+	StatusCode   int           // http status code. It's 0 if server is not reachable.
+	ErrorCode    int           // graph api error code in response.
+	ErrorSubcode int           // graph api error subcode in response.
+	TraceID      string        // fbtrace_id in graph api error or x-fb-trace-id header.
+	Usage        *UsageInfo    // usage information in response headers. It can be nil.
+	Err          error         // redacted transport error.
+}
+
+// SetLogger sets a logger to log every http request. Set logger to nil to stop logging.
+func (session *Session) SetLogger(logger Logger) {
+	session.logger = logger
+}
+
+// Logger returns current logger. It can be nil.
+func (session *Session) Logger() Logger {
+	return session.logger
+}
+
+// newLogEntry creates a log entry before request is sent.
+func newLogEntry(request *http.Request) *LogEntry {
+	entry := &LogEntry{
+		Method: request.Method,
+		Path:   request.URL.Path,
+		URL:    Redact(request.URL.String()),
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(request.Header.Get("Content-Type"))
+
+	if mediaType == "application/x-www-form-urlencoded" && request.GetBody != nil {
+		if body, err := request.GetBody(); err == nil {
+			data, _ := io.ReadAll(io.LimitReader(body, maxLogBodySize))
+			body.Close()
+			entry.RequestBody = Redact(string(data))
+		}
+	}
+
+	return entry
+}
+
+// logCall fills response information in entry and logs it.
+func (session *Session) logCall(entry *LogEntry, start time.Time, response *http.Response, data []byte, err error) {
+	entry.Duration = time.Since(start)
+	entry.Err = RedactError(err)
+
+	if response != nil {
+		entry.StatusCode = response.StatusCode
+		entry.TraceID = response.Header.Get("X-Fb-Trace-Id")
+		entry.Usage = usageFromResponse(session, response)
+
+		code, subcode, traceID := parseErrorCode(data)
+		entry.ErrorCode = code
+		entry.ErrorSubcode = subcode
+
+		if traceID != "" {
+			entry.TraceID = traceID
+		}
+	}
+
+	session.logger.LogCall(entry)
+}
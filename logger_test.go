@@ -0,0 +1,105 @@
+// A facebook graph api client in go.
+// https://github.com/huandu/facebook/
+//
+// Copyright 2012, Huan Du
+// Licensed under the MIT license
+// https://github.com/huandu/facebook/blob/master/LICENSE
+
+package facebook
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSessionLogger(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-App-Usage", `{"call_count":12}`)
+
+		if r.URL.Path == "/v19.0/me" {
+			fmt.Fprint(w, `{"id":"123"}`)
+			return
+		}
+
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"error":{"message":"invalid token","code":190,"error_subcode":463,"fbtrace_id":"trace"}}`)
+	}))
+	defer server.Close()
+
+	var entries []*LogEntry
+	app := New("123", "secret")
+	session := app.Session("secret-token")
+	session.BaseURL = server.URL + "/"
+	session.Version = "v19.0"
+	session.EnableAppsecretProof(true)
+	session.SetLogger(LoggerFunc(func(entry *LogEntry) {
+		entries = append(entries, entry)
+	}))
+
+	if _, err := session.Get("/me", Params{"fields": "id"}); err != nil {
+		t.Fatalf("fail to get /me. [e:%v]", err)
+	}
+
+	session.Post("/me/feed", Params{"message": "hello"})
+
+	if len(entries) != 2 {
+		t.Fatalf("every request must be logged. [entries:%v]", len(entries))
+	}
+
+	entry := entries[0]
+
+	if entry.Method != "GET" || entry.Path != "/v19.0/me" || entry.StatusCode != 200 || entry.Duration <= 0 {
+		t.Fatalf("invalid log entry. [entry:%#v]", entry)
+	}
+
+	if entry.Usage == nil || entry.Usage.App.CallCount != 12 {
+		t.Fatalf("usage must be logged. [usage:%v]", entry.Usage)
+	}
+
+	entry = entries[1]
+
+	if entry.StatusCode != 400 || entry.ErrorCode != 190 || entry.ErrorSubcode != 463 || entry.TraceID != "trace" {
+		t.Fatalf("invalid error log entry. [entry:%#v]", entry)
+	}
+
+	if !strings.Contains(entry.RequestBody, "message=hello") {
+		t.Fatalf("request body must be logged. [body:%v]", entry.RequestBody)
+	}
+
+	for _, e := range entries {
+		if strings.Contains(e.URL+e.RequestBody, "secret-token") || strings.Contains(e.URL+e.RequestBody, session.AppsecretProof()) {
+			t.Fatalf("secrets must be redacted. [url:%v] [body:%v]", e.URL, e.RequestBody)
+		}
+	}
+
+	session.SetLogger(nil)
+	session.Get("/me", nil)
+
+	if len(entries) != 2 {
+		t.Fatalf("logger must be removed.")
+	}
+}
+
+func TestSessionLoggerTransportError(t *testing.T) {
+	var entry *LogEntry
+	session := &Session{
+		BaseURL: "http://127.0.0.1:1/",
+	}
+	session.SetAccessToken("secret-token")
+	session.SetLogger(LoggerFunc(func(e *LogEntry) {
+		entry = e
+	}))
+
+	_, err := session.Get("/me", nil)
+
+	if err == nil || strings.Contains(err.Error(), "secret-token") {
+		t.Fatalf("transport error must be redacted. [e:%v]", err)
+	}
+
+	if entry == nil || entry.Err == nil || entry.StatusCode != 0 || strings.Contains(entry.Err.Error(), "secret-token") {
+		t.Fatalf("invalid log entry. [entry:%#v]", entry)
+	}
+}
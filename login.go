@@ -0,0 +1,277 @@
+// A facebook graph api client in go.
+// https://github.com/huandu/facebook/
+//
+// Copyright 2012, Huan Du
+// Licensed under the MIT license
+// https://github.com/huandu/facebook/blob/master/LICENSE
+
+package facebook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Login dialog response types.
+const (
+	ResponseTypeCode          = "code"
+	ResponseTypeToken         = "token"
+	ResponseTypeCodeToken     = "code token"
+	ResponseTypeGrantedScopes = "granted_scopes"
+)
+
+// Login dialog display modes.
+const (
+	DisplayPage  = "page"
+	DisplayPopup = "popup"
+	DisplayTouch = "touch"
+)
+
+// DefaultStateMaxAge is the default max age of a login state created by HMACStateStore.
+const DefaultStateMaxAge = 10 * time.Minute
+
+const (
+	stateNonceLength     = 16
+	stateTimestampLength = 8
+)
+
+var errEmptyStateSecret = fmt.Errorf("facebook: state secret is empty")
+
+// LoginOptions is the options to build a login dialog URL.
+//
+// Facebook document: https://developers.facebook.com/docs/facebook-login/guides/advanced/manual-flow
+type LoginOptions struct {
+	ResponseType string // response_type. Login dialog uses "code" if it's empty.
+	Rerequest    bool   // set auth_type=rerequest to ask for declined permissions again.
+	ConfigID     string // config_id of the configuration for Facebook Login for Business.
+	Display      string // display mode of the login dialog, e.g. DisplayPopup.
+	RedirectUri  string // redirect_uri. App.RedirectUri is used if it's empty.
+	PKCE         *PKCE  // code challenge created by App#NewPKCE.
+	Extra        Params // any other query string params in login dialog URL.
+}
+
+// StateStore creates and verifies the CSRF state used in login flow.
+//
+// The key is an opaque value which binds a state to a specific client,
+// e.g. browser session id stored in cookie. It can be empty if there is no such value.
+type StateStore interface {
+	NewState(key string) (state string, err error)
+	VerifyState(key, state string) error
+}
+
+// HMACStateStore is a stateless StateStore.
+// A state is a random nonce and its creation time signed by HMAC-SHA256 with Secret.
+type HMACStateStore struct {
+	Secret []byte        // secret to sign states.
+	MaxAge time.Duration // max age of a state. DefaultStateMaxAge is used if it's 0.
+}
+
+var _ StateStore = &HMACStateStore{}
+
+// NewHMACStateStore creates a HMACStateStore with secret and DefaultStateMaxAge.
+func NewHMACStateStore(secret string) *HMACStateStore {
+	return &HMACStateStore{
+		Secret: []byte(secret),
+	}
+}
+
+// NewState creates a new state bound to key.
+// Returns error if Secret is empty, as anyone can forge a state without secret.
+func (store *HMACStateStore) NewState(key string) (state string, err error) {
+	if len(store.Secret) == 0 {
+		err = errEmptyStateSecret
+		return
+	}
+
+	payload := make([]byte, stateNonceLength+stateTimestampLength)
+
+	if _, err = rand.Read(payload[:stateNonceLength]); err != nil {
+		err = fmt.Errorf("facebook: cannot generate random state; %w", err)
+		return
+	}
+
+	binary.BigEndian.PutUint64(payload[stateNonceLength:], uint64(time.Now().Unix()))
+	state = base64.RawURLEncoding.EncodeToString(payload) + "." +
+		base64.RawURLEncoding.EncodeToString(store.sign(key, payload))
+	return
+}
+
+// VerifyState verifies whether state is created by this store with the same key and is not expired.
+func (store *HMACStateStore) VerifyState(key, state string) error {
+	if len(store.Secret) == 0 {
+		return errEmptyStateSecret
+	}
+
+	strs := strings.SplitN(state, ".", 2)
+
+	if len(strs) != 2 {
+		return fmt.Errorf("facebook: invalid state format")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strs[0])
+
+	if err != nil || len(payload) != stateNonceLength+stateTimestampLength {
+		return fmt.Errorf("facebook: invalid state payload")
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(strs[1])
+
+	if err != nil || !hmac.Equal(sig, store.sign(key, payload)) {
+		return fmt.Errorf("facebook: bad state signature")
+	}
+
+	maxAge := store.MaxAge
+
+	if maxAge == 0 {
+		maxAge = DefaultStateMaxAge
+	}
+
+	created := time.Unix(int64(binary.BigEndian.Uint64(payload[stateNonceLength:])), 0)
+
+	if time.Since(created) > maxAge {
+		return fmt.Errorf("facebook: state is expired")
+	}
+
+	return nil
+}
+
+func (store *HMACStateStore) sign(key string, payload []byte) []byte {
+	hash := hmac.New(sha256.New, store.Secret)
+	hash.Write(payload)
+	hash.Write([]byte(key))
+	return hash.Sum(nil)
+}
+
+// LoginURL returns the login dialog URL to start a login flow.
+//
+// The state should be created by App#NewState and verified by App#VerifyState
+// or App#ParseLoginCallback when facebook redirects user back.
+// The opts can be nil.
+func (app *App) LoginURL(state string, scopes []string, opts *LoginOptions) string {
+	if opts == nil {
+		opts = &LoginOptions{}
+	}
+
+	params := Params{}
+
+	for k, v := range opts.Extra {
+		params[k] = v
+	}
+
+	redirectURI := opts.RedirectUri
+
+	if redirectURI == "" {
+		redirectURI = app.RedirectUri
+	}
+
+	params["client_id"] = app.AppId
+	params["redirect_uri"] = redirectURI
+
+	if state != "" {
+		params["state"] = state
+	}
+
+	if len(scopes) != 0 {
+		params["scope"] = strings.Join(scopes, ",")
+	}
+
+	if opts.ResponseType != "" {
+		params["response_type"] = opts.ResponseType
+	}
+
+	if opts.Rerequest {
+		params["auth_type"] = "rerequest"
+	}
+
+	if opts.ConfigID != "" {
+		params["config_id"] = opts.ConfigID
+	}
+
+	if opts.Display != "" {
+		params["display"] = opts.Display
+	}
+
+	if opts.PKCE != nil {
+		params["code_challenge"] = opts.PKCE.Challenge
+		params["code_challenge_method"] = opts.PKCE.ChallengeMethod
+	}
+
+	return app.getSession().getURL("www", "/dialog/oauth", params)
+}
+
+// NewState creates a new CSRF state bound to key for login flow.
+// If app.StateStore is not set, a HMACStateStore signing states with AppSecret is used.
+// In this case, NewState returns error if AppSecret is empty, e.g. a public client using PKCE,
+// and app.StateStore must be set.
+func (app *App) NewState(key string) (string, error) {
+	store, err := app.stateStore()
+
+	if err != nil {
+		return "", err
+	}
+
+	return store.NewState(key)
+}
+
+// VerifyState verifies a CSRF state created by NewState with the same key.
+func (app *App) VerifyState(key, state string) error {
+	store, err := app.stateStore()
+
+	if err != nil {
+		return err
+	}
+
+	return store.VerifyState(key, state)
+}
+
+// ParseLoginCallback verifies the state in query string of the redirect uri
+// and returns the code in it.
+// The code can be redeemed for an access token by ParseCode.
+//
+// Returns error if state is invalid or user doesn't authorize the app.
+func (app *App) ParseLoginCallback(query url.Values, key string) (code string, err error) {
+	if err = app.VerifyState(key, query.Get("state")); err != nil {
+		return
+	}
+
+	if e := query.Get("error"); e != "" {
+		err = fmt.Errorf("facebook: login failed with error '%v'; %v (error_reason: %v)",
+			e, query.Get("error_description"), query.Get("error_reason"))
+		return
+	}
+
+	code = query.Get("code")
+
+	if code == "" {
+		err = fmt.Errorf("facebook: code is empty")
+	}
+
+	return
+}
+
+func (app *App) stateStore() (StateStore, error) {
+	if app.StateStore != nil {
+		return app.StateStore, nil
+	}
+
+	if app.AppSecret == "" {
+		return nil, fmt.Errorf("facebook: cannot sign states without app secret; set App.StateStore instead")
+	}
+
+	return NewHMACStateStore(app.AppSecret), nil
+}
+
+func (app *App) getSession() *Session {
+	if app.session != nil {
+		return app.session
+	}
+
+	return defaultSession
+}
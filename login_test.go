@@ -0,0 +1,156 @@
+// A facebook graph api client in go.
+// https://github.com/huandu/facebook/
+//
+// Copyright 2012, Huan Du
+// Licensed under the MIT license
+// https://github.com/huandu/facebook/blob/master/LICENSE
+
+package facebook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAppLoginURL(t *testing.T) {
+	app := New("123", "secret")
+	app.RedirectUri = "https://example.com/callback"
+
+	loginURL := app.LoginURL("the-state", []string{"email", "public_profile"}, &LoginOptions{
+		ResponseType: ResponseTypeCode,
+		Rerequest:    true,
+		ConfigID:     "456",
+		Display:      DisplayPopup,
+	})
+	u, err := url.Parse(loginURL)
+
+	if err != nil {
+		t.Fatalf("invalid login url. [url:%v] [e:%v]", loginURL, err)
+	}
+
+	if u.Host != "www.facebook.com" || u.Path != "/dialog/oauth" {
+		t.Fatalf("invalid login url host or path. [url:%v]", loginURL)
+	}
+
+	expected := url.Values{
+		"client_id":     {"123"},
+		"redirect_uri":  {"https://example.com/callback"},
+		"state":         {"the-state"},
+		"scope":         {"email,public_profile"},
+		"response_type": {"code"},
+		"auth_type":     {"rerequest"},
+		"config_id":     {"456"},
+		"display":       {"popup"},
+	}
+
+	if query := u.Query(); query.Encode() != expected.Encode() {
+		t.Fatalf("invalid login url query. [expected:%v] [actual:%v]", expected, query)
+	}
+}
+
+func TestAppLoginState(t *testing.T) {
+	app := New("123", "secret")
+	state, err := app.NewState("browser-session")
+
+	if err != nil {
+		t.Fatalf("fail to create state. [e:%v]", err)
+	}
+
+	if err := app.VerifyState("browser-session", state); err != nil {
+		t.Fatalf("state must be valid. [state:%v] [e:%v]", state, err)
+	}
+
+	if err := app.VerifyState("another-session", state); err == nil {
+		t.Fatalf("state must be bound to the key.")
+	}
+
+	if err := New("123", "another-secret").VerifyState("browser-session", state); err == nil {
+		t.Fatalf("state must be signed by app secret.")
+	}
+
+	app.StateStore = &HMACStateStore{
+		Secret: []byte("secret"),
+		MaxAge: -time.Second,
+	}
+
+	if err := app.VerifyState("browser-session", state); err == nil {
+		t.Fatalf("state must be expired.")
+	}
+
+	app.StateStore = nil
+	code, err := app.ParseLoginCallback(url.Values{
+		"state": {state},
+		"code":  {"the-code"},
+	}, "browser-session")
+
+	if err != nil || code != "the-code" {
+		t.Fatalf("fail to parse login callback. [code:%v] [e:%v]", code, err)
+	}
+
+	_, err = app.ParseLoginCallback(url.Values{
+		"state":             {state},
+		"error":             {"access_denied"},
+		"error_reason":      {"user_denied"},
+		"error_description": {"Permissions error."},
+	}, "browser-session")
+
+	if err == nil {
+		t.Fatalf("login callback with error must fail.")
+	}
+
+	_, err = app.ParseLoginCallback(url.Values{
+		"state": {"forged"},
+		"code":  {"the-code"},
+	}, "browser-session")
+
+	if err == nil {
+		t.Fatalf("login callback with invalid state must fail.")
+	}
+}
+
+func TestAppLoginStateWithoutSecret(t *testing.T) {
+	app := New("123", "")
+
+	if _, err := app.NewState("browser-session"); err == nil {
+		t.Fatalf("app without secret must not create states.")
+	}
+
+	// a state signed with an empty key can be forged by anyone.
+	forged, _ := (&HMACStateStore{Secret: []byte("x")}).NewState("browser-session")
+	payload := forged[:strings.Index(forged, ".")]
+	data, _ := base64.RawURLEncoding.DecodeString(payload)
+	hash := hmac.New(sha256.New, nil)
+	hash.Write(data)
+	hash.Write([]byte("browser-session"))
+	forged = payload + "." + base64.RawURLEncoding.EncodeToString(hash.Sum(nil))
+
+	if err := app.VerifyState("browser-session", forged); err == nil {
+		t.Fatalf("app without secret must not verify states.")
+	}
+
+	store := &HMACStateStore{}
+
+	if _, err := store.NewState("browser-session"); err == nil {
+		t.Fatalf("store without secret must not create states.")
+	}
+
+	if err := store.VerifyState("browser-session", forged); err == nil {
+		t.Fatalf("store without secret must not verify states.")
+	}
+
+	app.StateStore = NewHMACStateStore("state-secret")
+	state, err := app.NewState("browser-session")
+
+	if err != nil {
+		t.Fatalf("app with a state store must create states. [e:%v]", err)
+	}
+
+	if err := app.VerifyState("browser-session", state); err != nil {
+		t.Fatalf("state must be valid. [e:%v]", err)
+	}
+}
@@ -0,0 +1,300 @@
+// A facebook graph api client in go.
+// https://github.com/huandu/facebook/
+//
+// Copyright 2012, Huan Du
+// Licensed under the MIT license
+// https://github.com/huandu/facebook/blob/master/LICENSE
+
+package facebook
+
+import (
+	"fmt"
+	"sync"
+)
+
+const managedPageFields = "id,name,category,access_token,tasks,instagram_business_account{id,username}"
+
+// ManagedPage is a page managed by a user or system user.
+type ManagedPage struct {
+	ID          string
+	Name        string
+	Category    string
+	AccessToken string   `facebook:"access_token"` // page access token. It's empty if user cannot get the token.
+	Tasks       []string // tasks user can perform on the page, e.g. "MANAGE" or "CREATE_CONTENT".
+	BusinessID  string   `facebook:"-"` // id of the business owning the page. It's empty for pages in /me/accounts.
+
+	InstagramBusinessAccount *ManagedInstagramAccount `facebook:"instagram_business_account"`
+}
+
+// ManagedInstagramAccount is an Instagram business account managed by a user or system user.
+type ManagedInstagramAccount struct {
+	ID         string
+	Username   string
+	PageID     string `facebook:"-"` // id of the connected page. Use its page session to manage the account.
+	BusinessID string `facebook:"-"` // id of the business owning the account.
+}
+
+// PageTokens enumerates pages and Instagram business accounts managed by the user
+// of a session and caches a session per page.
+//
+// Page sessions share HttpClient, Version, BaseURL and appsecret proof settings
+// with the user session. All pages are reloaded when the user token changes.
+//
+// PageTokens is safe for concurrent use.
+type PageTokens struct {
+	// Enumerate pages and Instagram accounts owned by user's businesses as well.
+	// It requires business_management permission.
+	IncludeBusinessAssets bool
+
+	session *Session
+
+	mu        sync.Mutex
+	userToken string
+	pages     []*ManagedPage
+	instagram []*ManagedInstagramAccount
+	sessions  map[string]*Session
+}
+
+// NewPageTokens creates a PageTokens for the user or system user of session.
+func NewPageTokens(session *Session) *PageTokens {
+	return &PageTokens{
+		session: session,
+	}
+}
+
+// Pages returns all managed pages.
+func (pt *PageTokens) Pages() ([]ManagedPage, error) {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+
+	if err := pt.load(); err != nil {
+		return nil, err
+	}
+
+	pages := make([]ManagedPage, 0, len(pt.pages))
+
+	for _, page := range pt.pages {
+		pages = append(pages, *page)
+	}
+
+	return pages, nil
+}
+
+// InstagramAccounts returns all managed Instagram business accounts.
+func (pt *PageTokens) InstagramAccounts() ([]ManagedInstagramAccount, error) {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+
+	if err := pt.load(); err != nil {
+		return nil, err
+	}
+
+	accounts := make([]ManagedInstagramAccount, 0, len(pt.instagram))
+
+	for _, account := range pt.instagram {
+		accounts = append(accounts, *account)
+	}
+
+	return accounts, nil
+}
+
+// Session returns a cached session with page access token of pageID.
+func (pt *PageTokens) Session(pageID string) (*Session, error) {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+
+	if err := pt.load(); err != nil {
+		return nil, err
+	}
+
+	if session, ok := pt.sessions[pageID]; ok {
+		return session, nil
+	}
+
+	for _, page := range pt.pages {
+		if page.ID != pageID {
+			continue
+		}
+
+		if page.AccessToken == "" {
+			return nil, fmt.Errorf("facebook: no access token for page '%v'", pageID)
+		}
+
+		session := pt.pageSession(page.AccessToken)
+		pt.sessions[pageID] = session
+		return session, nil
+	}
+
+	return nil, fmt.Errorf("facebook: page '%v' is not managed by current user", pageID)
+}
+
+// Refresh drops all cached pages and sessions and reloads them.
+func (pt *PageTokens) Refresh() error {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+
+	pt.userToken = ""
+	return pt.load()
+}
+
+// load reloads pages if user token has changed.
+func (pt *PageTokens) load() error {
+	cred, err := pt.session.resolveCredential()
+
+	if err != nil {
+		return err
+	}
+
+	userToken := cred.accessToken
+
+	if pt.pages != nil && userToken == pt.userToken {
+		return nil
+	}
+
+	pages, err := pt.fetchPages("/me/accounts", "")
+
+	if err != nil {
+		return err
+	}
+
+	var instagram []*ManagedInstagramAccount
+
+	if pt.IncludeBusinessAssets {
+		var businesses []struct {
+			ID string
+		}
+
+		if err = pt.session.decodeAllData("/me/businesses", Params{"fields": "id"}, &businesses); err != nil {
+			return err
+		}
+
+		for _, business := range businesses {
+			owned, err := pt.fetchPages(fmt.Sprintf("/%v/owned_pages", business.ID), business.ID)
+
+			if err != nil {
+				return err
+			}
+
+			pages = append(pages, owned...)
+
+			var accounts []*ManagedInstagramAccount
+			err = pt.session.decodeAllData(fmt.Sprintf("/%v/owned_instagram_accounts", business.ID), Params{
+				"fields": "id,username",
+			}, &accounts)
+
+			if err != nil {
+				return err
+			}
+
+			for _, account := range accounts {
+				account.BusinessID = business.ID
+			}
+
+			instagram = append(instagram, accounts...)
+		}
+	}
+
+	pt.setAssets(pages, instagram)
+	pt.userToken = userToken
+	return nil
+}
+
+func (pt *PageTokens) fetchPages(path, businessID string) ([]*ManagedPage, error) {
+	var pages []*ManagedPage
+	err := pt.session.decodeAllData(path, Params{"fields": managedPageFields}, &pages)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, page := range pages {
+		page.BusinessID = businessID
+	}
+
+	return pages, nil
+}
+
+// setAssets dedups pages and Instagram accounts and resets cached sessions.
+// A page with access token takes precedence over the same page without token.
+func (pt *PageTokens) setAssets(pages []*ManagedPage, instagram []*ManagedInstagramAccount) {
+	pageIndex := map[string]int{}
+	pt.pages = []*ManagedPage{}
+
+	for _, page := range pages {
+		if i, ok := pageIndex[page.ID]; ok {
+			if pt.pages[i].AccessToken == "" && page.AccessToken != "" {
+				pt.pages[i] = page
+			}
+
+			continue
+		}
+
+		pageIndex[page.ID] = len(pt.pages)
+		pt.pages = append(pt.pages, page)
+	}
+
+	accountIndex := map[string]int{}
+	pt.instagram = []*ManagedInstagramAccount{}
+	add := func(account *ManagedInstagramAccount) {
+		if i, ok := accountIndex[account.ID]; ok {
+			existing := pt.instagram[i]
+
+			if existing.PageID == "" {
+				existing.PageID = account.PageID
+			}
+
+			if existing.BusinessID == "" {
+				existing.BusinessID = account.BusinessID
+			}
+
+			return
+		}
+
+		accountIndex[account.ID] = len(pt.instagram)
+		pt.instagram = append(pt.instagram, account)
+	}
+
+	for _, page := range pt.pages {
+		if page.InstagramBusinessAccount != nil && page.InstagramBusinessAccount.ID != "" {
+			account := *page.InstagramBusinessAccount
+			account.PageID = page.ID
+			account.BusinessID = page.BusinessID
+			add(&account)
+		}
+	}
+
+	for _, account := range instagram {
+		add(account)
+	}
+
+	pt.sessions = map[string]*Session{}
+}
+
+// pageSession creates a session sharing settings with user session.
+func (pt *PageTokens) pageSession(accessToken string) *Session {
+	state := pt.session.state()
+	state.mu.RLock()
+	enableAppsecretProof := pt.session.enableAppsecretProof
+	useAuthorizationHeader := pt.session.useAuthorizationHeader
+	debug := pt.session.debug
+	state.mu.RUnlock()
+
+	session := &Session{
+		HttpClient:             pt.session.HttpClient,
+		Version:                pt.session.Version,
+		RFC3339Timestamps:      pt.session.RFC3339Timestamps,
+		BaseURL:                pt.session.BaseURL,
+		Instagram:              pt.session.Instagram,
+		app:                    pt.session.app,
+		enableAppsecretProof:   enableAppsecretProof,
+		useAuthorizationHeader: useAuthorizationHeader,
+		debug:                  debug,
+		logger:                 pt.session.logger,
+		instrumenter:           pt.session.instrumenter,
+		versionWatcher:         pt.session.versionWatcher,
+		debugMessageWatcher:    pt.session.debugMessageWatcher,
+		context:                pt.session.context,
+	}
+	session.SetAccessToken(accessToken)
+	return session
+}
@@ -0,0 +1,106 @@
+// A facebook graph api client in go.
+// https://github.com/huandu/facebook/
+//
+// Copyright 2012, Huan Du
+// Licensed under the MIT license
+// https://github.com/huandu/facebook/blob/master/LICENSE
+
+package facebook
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPageTokens(t *testing.T) {
+	requests := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.URL.Query().Get("access_token")
+		requests[r.URL.Path+"@"+token]++
+
+		switch r.URL.Path {
+		case "/v19.0/me/accounts":
+			fmt.Fprintf(w, `{"data":[
+				{"id":"1","name":"Page 1","access_token":"page-1-%v","tasks":["MANAGE"],"instagram_business_account":{"id":"ig1","username":"one"}},
+				{"id":"2","name":"Page 2"}
+			]}`, token)
+		case "/v19.0/me/businesses":
+			fmt.Fprint(w, `{"data":[{"id":"b1"}]}`)
+		case "/v19.0/b1/owned_pages":
+			fmt.Fprint(w, `{"data":[{"id":"1","name":"Page 1"},{"id":"2","name":"Page 2","access_token":"page-2"},{"id":"3","name":"Page 3"}]}`)
+		case "/v19.0/b1/owned_instagram_accounts":
+			fmt.Fprint(w, `{"data":[{"id":"ig1","username":"one"},{"id":"ig2","username":"two"}]}`)
+		default:
+			t.Errorf("unexpected path. [path:%v]", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	app := New("123", "secret")
+	session := app.Session("user-1")
+	session.BaseURL = server.URL + "/"
+	session.Version = "v19.0"
+	session.EnableAppsecretProof(true)
+
+	pt := NewPageTokens(session)
+	pt.IncludeBusinessAssets = true
+	pages, err := pt.Pages()
+
+	if err != nil {
+		t.Fatalf("fail to get pages. [e:%v]", err)
+	}
+
+	if len(pages) != 3 || pages[0].AccessToken != "page-1-user-1" || pages[0].Tasks[0] != "MANAGE" ||
+		pages[1].AccessToken != "page-2" || pages[1].BusinessID != "b1" || pages[2].ID != "3" {
+		t.Fatalf("invalid pages. [pages:%#v]", pages)
+	}
+
+	accounts, err := pt.InstagramAccounts()
+
+	if err != nil {
+		t.Fatalf("fail to get instagram accounts. [e:%v]", err)
+	}
+
+	if len(accounts) != 2 || accounts[0].PageID != "1" || accounts[0].BusinessID != "b1" || accounts[1].ID != "ig2" {
+		t.Fatalf("invalid instagram accounts. [accounts:%#v]", accounts)
+	}
+
+	pageSession, err := pt.Session("1")
+
+	if err != nil {
+		t.Fatalf("fail to get page session. [e:%v]", err)
+	}
+
+	if pageSession.AccessToken() != "page-1-user-1" || pageSession.AppsecretProof() == "" || pageSession.BaseURL != session.BaseURL {
+		t.Fatalf("invalid page session. [token:%v]", pageSession.AccessToken())
+	}
+
+	if s, _ := pt.Session("1"); s != pageSession {
+		t.Fatalf("page session must be cached.")
+	}
+
+	if _, err := pt.Session("3"); err == nil {
+		t.Fatalf("page without access token must fail.")
+	}
+
+	if _, err := pt.Session("4"); err == nil {
+		t.Fatalf("unknown page must fail.")
+	}
+
+	if requests["/v19.0/me/accounts@user-1"] != 1 {
+		t.Fatalf("pages must be cached. [requests:%v]", requests)
+	}
+
+	session.SetAccessToken("user-2")
+	newSession, err := pt.Session("1")
+
+	if err != nil {
+		t.Fatalf("fail to get page session. [e:%v]", err)
+	}
+
+	if newSession == pageSession || newSession.AccessToken() != "page-1-user-2" {
+		t.Fatalf("page session must be refreshed when user token changes. [token:%v]", newSession.AccessToken())
+	}
+}
@@ -112,7 +112,12 @@ func (pr *PagingResult) navigate(url *string) (noMore bool, err error) {
 
 	// add session information in paging url.
 	params := Params{}
-	pr.session.prepareParams(params)
+
+	cred, err := pr.session.prepareParams(params)
+
+	if err != nil {
+		return
+	}
 
 	// Per #182, access_token is always useless.
 	// As we may need to keep other params, do a manual delete here.
@@ -138,7 +143,7 @@ func (pr *PagingResult) navigate(url *string) (noMore bool, err error) {
 		return
 	}
 
-	res, err = pr.session.Request(request)
+	res, err = pr.session.request(request, cred)
 
 	if err != nil {
 		return
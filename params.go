@@ -8,6 +8,7 @@
 package facebook
 
 import (
+	"encoding"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -19,7 +20,9 @@ import (
 	"path"
 	"reflect"
 	"runtime"
+	"sort"
 	"strings"
+	"time"
 )
 
 const (
@@ -30,6 +33,9 @@ const (
 var (
 	typeOfPointerToBinaryData = reflect.TypeOf(&BinaryData{})
 	typeOfPointerToBinaryFile = reflect.TypeOf(&BinaryFile{})
+	typeOfTime                = reflect.TypeOf(time.Time{})
+	typeOfTextMarshaler       = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	typeOfStringer            = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
 )
 
 // Params is the params used to send Facebook API request.
@@ -44,10 +50,41 @@ type Params map[string]interface{}
 // MakeParams will change all struct field name to lower case name with underscore.
 // e.g. "FooBar" will be changed to "foo_bar".
 //
+// See MarshalParams for supported struct field tags.
+//
 // Returns nil if data cannot be used to make a Params instance.
+// Use MarshalParams to get the reason.
 func MakeParams(data interface{}) (params Params) {
+	params, _ = MarshalParams(data)
+	return
+}
+
+// MarshalParams makes a new Params instance by given data.
+// Data must be a struct or a map with string keys.
+//
+// The encoding of each struct field can be customized by the format string stored
+// under the "facebook" key or the "json" key in the struct field's tag.
+// The format string gives the name of the field, possibly followed by a
+// comma-separated list of options. Supported options are:
+//
+//   - omitempty: the field is omitted if it has an empty value.
+//   - string: a bool, integer or float field is encoded as a string.
+//   - csv: items in a slice or array field are joined with comma, e.g. "id,name".
+//   - unixtime: a time.Time field is encoded as unix timestamp in seconds.
+//   - json: the field is marshalled to a json string by json.Marshal.
+//
+// A field with tag `facebook:"-"` is always omitted.
+// An embedded struct without a name in field tag is expanded as if its fields
+// were in the outer struct.
+//
+// Without options, a time.Time field is encoded in RFC3339 format and a field
+// implementing encoding.TextMarshaler or fmt.Stringer is encoded as the text it returns.
+// A nested struct is converted to Params recursively and nil pointers are omitted.
+//
+// Returns error if data or any of its fields cannot be encoded, e.g. a chan or func field.
+func MarshalParams(data interface{}) (params Params, err error) {
 	if p, ok := data.(Params); ok {
-		return p
+		return p, nil
 	}
 
 	defer func() {
@@ -57,15 +94,29 @@ func MakeParams(data interface{}) (params Params) {
 			}
 
 			params = nil
+			err = fmt.Errorf("facebook: cannot make params; %v", r)
 		}
 	}()
 
-	params = makeParams(reflect.ValueOf(data))
+	params, err = makeParams(reflect.ValueOf(data))
 	return
 }
 
-func makeParams(value reflect.Value) (params Params) {
+type paramsFieldOptions struct {
+	omitEmpty bool
+	asString  bool
+	csv       bool
+	unixTime  bool
+	json      bool
+}
+
+func makeParams(value reflect.Value) (params Params, err error) {
 	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			err = fmt.Errorf("facebook: cannot make params from a nil value")
+			return
+		}
+
 		value = value.Elem()
 	}
 
@@ -81,57 +132,54 @@ func makeParams(value reflect.Value) (params Params) {
 	}
 
 	if value.Kind() != reflect.Struct {
+		err = fmt.Errorf("facebook: cannot make params from type %v; data must be a struct or a map with string keys", value.Type())
 		return
 	}
 
 	params = Params{}
+	err = makeParamsFields(params, value, false)
+
+	if err != nil {
+		params = nil
+	}
+
+	return
+}
+
+func makeParamsFields(params Params, value reflect.Value, embedded bool) error {
 	t := value.Type()
 	num := value.NumField()
 
 	for i := 0; i < num; i++ {
 		sf := t.Field(i)
+		name, opts, skip := parseParamsTag(sf)
 
-		// Ignore field if it's not exported
-		if !sf.IsExported() {
+		if skip {
 			continue
 		}
-		tag := sf.Tag
-		name := ""
-		omitEmpty := false
-
-		// If field tag "facebook" or "json" exists, use it as field name and options.
-		fbTag := tag.Get("facebook")
-		jsonTag := tag.Get("json")
 
-		if fbTag != "" || jsonTag != "" {
-			optTag := jsonTag
+		field := value.Field(i)
 
-			// If field tag "facebook" exists, it's preferred.
-			if fbTag != "" {
-				optTag = fbTag
+		// embedded struct is expanded unless it has a name in field tag.
+		if sf.Anonymous && name == "" && isExpandableStruct(sf.Type) {
+			for field.Kind() == reflect.Ptr {
+				field = field.Elem()
 			}
 
-			opts := strings.Split(optTag, ",")
-
-			if opts[0] != "" {
-				name = opts[0]
+			if !field.IsValid() {
+				continue
 			}
 
-			for _, opt := range opts[1:] {
-				if opt == "omitempty" {
-					omitEmpty = true
-				}
+			if err := makeParamsFields(params, field, true); err != nil {
+				return err
 			}
-		}
 
-		field := value.Field(i)
-
-		if omitEmpty && isEmptyValue(field) {
 			continue
 		}
 
-		for field.Kind() == reflect.Ptr {
-			field = field.Elem()
+		// Ignore field if it's not exported
+		if !sf.IsExported() {
+			continue
 		}
 
 		// If name is not set in field tag, use field name directly.
@@ -139,23 +187,259 @@ func makeParams(value reflect.Value) (params Params) {
 			name = camelCaseToUnderScore(sf.Name)
 		}
 
-		switch field.Kind() {
-		case reflect.Chan, reflect.Func, reflect.UnsafePointer, reflect.Invalid:
-			// these types won't be marshalled in json.
-			params = nil
+		// fields in outer struct take precedence over fields in embedded struct.
+		if _, ok := params[name]; ok && embedded {
+			continue
+		}
+
+		if opts.omitEmpty && isEmptyValue(field) {
+			continue
+		}
+
+		v, ok, err := marshalParamsValue(field, opts)
+
+		if err != nil {
+			return fmt.Errorf("facebook: cannot make params for field '%v'; %w", sf.Name, err)
+		}
+
+		if !ok {
+			continue
+		}
+
+		params[name] = v
+	}
+
+	return nil
+}
+
+func parseParamsTag(sf reflect.StructField) (name string, opts paramsFieldOptions, skip bool) {
+	tag := sf.Tag
+
+	// If field tag "facebook" or "json" exists, use it as field name and options.
+	optTag := tag.Get("facebook")
+
+	// If field tag "facebook" exists, it's preferred.
+	if optTag == "" {
+		optTag = tag.Get("json")
+	}
+
+	if optTag == "-" {
+		skip = true
+		return
+	}
+
+	if optTag == "" {
+		return
+	}
+
+	parts := strings.Split(optTag, ",")
+	name = parts[0]
+
+	for _, opt := range parts[1:] {
+		switch opt {
+		case "omitempty":
+			opts.omitEmpty = true
+		case "string":
+			opts.asString = true
+		case "csv":
+			opts.csv = true
+		case "unixtime":
+			opts.unixTime = true
+		case "json":
+			opts.json = true
+		}
+	}
+
+	return
+}
+
+func isExpandableStruct(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t.Kind() == reflect.Struct && !isParamsTextType(t)
+}
+
+func isParamsTextType(t reflect.Type) bool {
+	return t == typeOfTime ||
+		t.Implements(typeOfTextMarshaler) || reflect.PtrTo(t).Implements(typeOfTextMarshaler) ||
+		t.Implements(typeOfStringer) || reflect.PtrTo(t).Implements(typeOfStringer)
+}
+
+// marshalParamsValue converts a struct field value to a Params value.
+// It returns false if the value should be omitted.
+func marshalParamsValue(value reflect.Value, opts paramsFieldOptions) (v interface{}, ok bool, err error) {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
 			return
+		}
 
-		case reflect.Struct:
-			params[name] = makeParams(field)
+		// binary data must be kept as is so that Params#Encode can encode it as multipart form.
+		if typ := value.Type(); typ == typeOfPointerToBinaryData || typ == typeOfPointerToBinaryFile {
+			v, ok = value.Interface(), true
+			return
+		}
 
-		default:
-			params[name] = field.Interface()
+		value = value.Elem()
+	}
+
+	if !value.IsValid() {
+		return
+	}
+
+	ok = true
+
+	if typ := value.Type(); typ == typeOfPointerToBinaryData.Elem() || typ == typeOfPointerToBinaryFile.Elem() {
+		ptr := reflect.New(typ)
+		ptr.Elem().Set(value)
+		v = ptr.Interface()
+		return
+	}
+
+	switch {
+	case opts.json:
+		var data []byte
+		data, err = json.Marshal(value.Interface())
+		v = string(data)
+		return
+
+	case opts.unixTime:
+		if value.Type() != typeOfTime {
+			err = fmt.Errorf("option 'unixtime' requires a time.Time value but got %v", value.Type())
+			return
+		}
+
+		v = value.Interface().(time.Time).Unix()
+		return
+
+	case opts.csv:
+		kind := value.Kind()
+
+		if kind != reflect.Slice && kind != reflect.Array {
+			err = fmt.Errorf("option 'csv' requires a slice or array value but got %v", value.Type())
+			return
+		}
+
+		items := make([]string, value.Len())
+
+		for i := range items {
+			items[i], err = formatParamsText(value.Index(i))
+
+			if err != nil {
+				return
+			}
 		}
+
+		v = strings.Join(items, ",")
+		return
+	}
+
+	switch value.Kind() {
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		// these types won't be marshalled in json.
+		err = fmt.Errorf("type %v is not supported", value.Type())
+		return
+	}
+
+	if isParamsTextType(value.Type()) {
+		v, err = formatParamsText(value)
+		return
+	}
+
+	if opts.asString {
+		switch value.Kind() {
+		case reflect.Bool,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+			reflect.Float32, reflect.Float64:
+			var data []byte
+			data, err = json.Marshal(value.Interface())
+			v = string(data)
+			return
+		}
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		v, err = makeParams(value)
+
+	case reflect.Slice, reflect.Array:
+		if !isExpandableStruct(value.Type().Elem()) {
+			v = value.Interface()
+			return
+		}
+
+		if value.Kind() == reflect.Slice && value.IsNil() {
+			ok = false
+			return
+		}
+
+		items := make([]interface{}, value.Len())
+
+		for i := range items {
+			items[i], _, err = marshalParamsValue(value.Index(i), paramsFieldOptions{})
+
+			if err != nil {
+				return
+			}
+		}
+
+		v = items
+
+	default:
+		v = value.Interface()
 	}
 
 	return
 }
 
+// formatParamsText formats a value as a plain string.
+func formatParamsText(value reflect.Value) (string, error) {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return "", nil
+		}
+
+		value = value.Elem()
+	}
+
+	if !value.IsValid() {
+		return "", nil
+	}
+
+	if value.Type() == typeOfTime {
+		return value.Interface().(time.Time).Format(time.RFC3339), nil
+	}
+
+	if !value.CanAddr() {
+		// make value addressable to call methods with pointer receiver.
+		ptr := reflect.New(value.Type())
+		ptr.Elem().Set(value)
+		value = ptr.Elem()
+	}
+
+	if tm, ok := value.Addr().Interface().(encoding.TextMarshaler); ok {
+		text, err := tm.MarshalText()
+
+		if err != nil {
+			return "", err
+		}
+
+		return string(text), nil
+	}
+
+	if s, ok := value.Addr().Interface().(fmt.Stringer); ok {
+		return s.String(), nil
+	}
+
+	if value.Kind() == reflect.String {
+		return value.String(), nil
+	}
+
+	return fmt.Sprint(value.Interface()), nil
+}
+
 func isEmptyValue(v reflect.Value) bool {
 	switch v.Kind() {
 	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
@@ -170,6 +454,10 @@ func isEmptyValue(v reflect.Value) bool {
 		return v.Float() == 0
 	case reflect.Interface, reflect.Ptr:
 		return v.IsNil()
+	case reflect.Struct:
+		if v.Type() == typeOfTime {
+			return v.Interface().(time.Time).IsZero()
+		}
 	}
 
 	return false
@@ -178,6 +466,9 @@ func isEmptyValue(v reflect.Value) bool {
 // Encode encodes params to query string.
 // If map value is not a string, Encode uses json.Marshal() to convert value to string.
 //
+// Keys are always encoded in sorted order, so the same Params always produces
+// the same query string or form fields.
+//
 // Encode may panic if Params contains values that cannot be marshalled to json string.
 func (params Params) Encode(writer io.Writer) (mime string, err error) {
 	if len(params) == 0 {
@@ -204,11 +495,71 @@ func (params Params) Encode(writer io.Writer) (mime string, err error) {
 	return params.encodeFormURLEncoded(writer)
 }
 
+// EncodeString encodes params to a query string for debugging purpose.
+//
+// Unlike Encode, binary data is never encoded as multipart form.
+// BinaryData and BinaryFile are replaced by their file names and
+// any value which cannot be marshalled to json string is formatted by fmt.
+func (params Params) EncodeString() string {
+	buf := &strings.Builder{}
+
+	for _, k := range params.sortedKeys() {
+		v := params[k]
+
+		if v == nil {
+			continue
+		}
+
+		if buf.Len() != 0 {
+			buf.WriteRune('&')
+		}
+
+		buf.WriteString(url.QueryEscape(k))
+		buf.WriteRune('=')
+
+		var str string
+
+		switch value := v.(type) {
+		case *BinaryData:
+			str = "@" + value.Filename
+
+		case *BinaryFile:
+			str = "@" + value.Filename
+
+		default:
+			if reflect.TypeOf(v).Kind() == reflect.String {
+				str = reflect.ValueOf(v).String()
+			} else if jsonStr, err := json.Marshal(v); err == nil {
+				str = string(jsonStr)
+			} else {
+				str = fmt.Sprint(v)
+			}
+		}
+
+		buf.WriteString(url.QueryEscape(str))
+	}
+
+	return buf.String()
+}
+
+func (params Params) sortedKeys() []string {
+	keys := make([]string, 0, len(params))
+
+	for k := range params {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
 func (params Params) encodeFormURLEncoded(writer io.Writer) (mime string, err error) {
 	var jsonStr []byte
 	written := false
 
-	for k, v := range params {
+	for _, k := range params.sortedKeys() {
+		v := params[k]
+
 		if v == nil {
 			continue
 		}
@@ -246,7 +597,13 @@ func (params Params) encodeMultipartForm(writer io.Writer) (mime string, err err
 		mime = w.FormDataContentType()
 	}()
 
-	for k, v := range params {
+	for _, k := range params.sortedKeys() {
+		v := params[k]
+
+		if v == nil {
+			continue
+		}
+
 		switch value := v.(type) {
 		case *BinaryData:
 			var dst io.Writer
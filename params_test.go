@@ -10,7 +10,9 @@ package facebook
 import (
 	"bytes"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestParamsEncode(t *testing.T) {
@@ -71,3 +73,174 @@ func TestParamsEncode(t *testing.T) {
 		t.Fatalf("complex encode result is '%v'. [e:%v] [mime:%v]", buf.String(), err, mime)
 	}
 }
+
+func TestParamsEncodeSorted(t *testing.T) {
+	params := Params{
+		"zzz":  "last",
+		"aaa":  "first",
+		"mmm":  123,
+		"nil":  nil,
+		"list": []string{"a", "b"},
+	}
+	expected := "aaa=first&list=%5B%22a%22%2C%22b%22%5D&mmm=123&zzz=last"
+
+	for i := 0; i < 10; i++ {
+		buf := &bytes.Buffer{}
+
+		if _, err := params.Encode(buf); err != nil || buf.String() != expected {
+			t.Fatalf("params must be encoded in sorted order. [expected:%v] [actual:%v] [e:%v]", expected, buf.String(), err)
+		}
+	}
+
+	if str := params.EncodeString(); str != expected {
+		t.Fatalf("invalid encoded string. [expected:%v] [actual:%v]", expected, str)
+	}
+
+	params["file"] = File("cat.jpg")
+	expected = "aaa=first&file=%40cat.jpg&list=%5B%22a%22%2C%22b%22%5D&mmm=123&zzz=last"
+
+	if str := params.EncodeString(); str != expected {
+		t.Fatalf("invalid encoded string with binary file. [expected:%v] [actual:%v]", expected, str)
+	}
+
+	session := &Session{Version: "v3.0"}
+	url := session.getURL("graph", "/me", Params{"b": "2", "a": "1", "c": "3"})
+	expected = "https://graph.facebook.com/v3.0/me?a=1&b=2&c=3"
+
+	if url != expected {
+		t.Fatalf("url query must be sorted. [expected:%v] [actual:%v]", expected, url)
+	}
+}
+
+type paramsEnum int
+
+func (e paramsEnum) String() string {
+	return [...]string{"zero", "one", "two"}[e]
+}
+
+type paramsEmbedded struct {
+	Inner  string
+	Shadow string
+}
+
+type paramsMarshalStruct struct {
+	paramsEmbedded
+
+	Shadow   string
+	Skipped  string `facebook:"-"`
+	Created  time.Time
+	Since    time.Time   `facebook:"since,unixtime"`
+	Until    *time.Time  `facebook:"until,unixtime,omitempty"`
+	Empty    time.Time   `facebook:",omitempty"`
+	Enum     paramsEnum  `facebook:"enum"`
+	Fields   []string    `facebook:"fields,csv"`
+	IDs      []int64     `facebook:"ids,csv"`
+	Limit    int         `facebook:"limit,string"`
+	Targets  interface{} `facebook:"targets,json"`
+	Nested   []ParamsNestedStruct
+	Optional *string
+}
+
+func TestMarshalParams(t *testing.T) {
+	created := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	data := &paramsMarshalStruct{
+		paramsEmbedded: paramsEmbedded{
+			Inner:  "inner",
+			Shadow: "shadowed",
+		},
+		Shadow:  "outer",
+		Skipped: "skipped",
+		Created: created,
+		Since:   created,
+		Enum:    2,
+		Fields:  []string{"id", "name"},
+		IDs:     []int64{1, 2},
+		Limit:   10,
+		Targets: map[string]interface{}{"countries": []string{"US"}},
+		Nested: []ParamsNestedStruct{
+			{AAA: 1, BBB: "b"},
+		},
+	}
+	params, err := MarshalParams(data)
+
+	if err != nil {
+		t.Fatalf("fail to marshal params. [e:%v]", err)
+	}
+
+	expected := Params{
+		"inner":   "inner",
+		"shadow":  "outer",
+		"created": "2020-01-02T03:04:05Z",
+		"since":   created.Unix(),
+		"enum":    "two",
+		"fields":  "id,name",
+		"ids":     "1,2",
+		"limit":   "10",
+		"targets": `{"countries":["US"]}`,
+		"nested": []interface{}{
+			Params{"aaa": 1, "bbb": "b", "ccc": false},
+		},
+	}
+
+	if !reflect.DeepEqual(params, expected) {
+		t.Fatalf("invalid marshalled params. [expected:%#v] [actual:%#v]", expected, params)
+	}
+
+	// chan and func cannot be marshalled.
+	invalid := struct {
+		Name     string
+		Callback func()
+	}{
+		Name: "invalid",
+	}
+
+	if params, err := MarshalParams(invalid); err == nil || params != nil {
+		t.Fatalf("func field must cause an error. [params:%v] [e:%v]", params, err)
+	}
+
+	if params := MakeParams(invalid); params != nil {
+		t.Fatalf("MakeParams must return nil on error. [params:%v]", params)
+	}
+
+	if _, err := MarshalParams(123); err == nil {
+		t.Fatalf("only struct or map can be marshalled.")
+	}
+
+	wrongOption := struct {
+		Since string `facebook:",unixtime"`
+	}{}
+
+	if _, err := MarshalParams(wrongOption); err == nil {
+		t.Fatalf("option unixtime requires a time.Time value.")
+	}
+}
+
+func TestMarshalParamsBinary(t *testing.T) {
+	file := File("a.jpg")
+	data := Data("b.txt", strings.NewReader("content"))
+	params, err := MarshalParams(struct {
+		Source *BinaryFile
+		Data   *BinaryData
+		Value  BinaryFile
+	}{file, data, *file})
+
+	if err != nil {
+		t.Fatalf("fail to marshal params. [e:%v]", err)
+	}
+
+	if params["source"] != file || params["data"] != data {
+		t.Fatalf("binary data must be kept as is. [params:%#v]", params)
+	}
+
+	if value, ok := params["value"].(*BinaryFile); !ok || value.Filename != "a.jpg" {
+		t.Fatalf("binary file value must be converted to a pointer. [params:%#v]", params)
+	}
+
+	params, _ = MarshalParams(struct{ Data *BinaryData }{data})
+	buf := &bytes.Buffer{}
+	mime, err := params.Encode(buf)
+
+	if err != nil || !strings.HasPrefix(mime, "multipart/form-data") || !strings.Contains(buf.String(), "content") {
+		t.Fatalf("params with binary data must be encoded as multipart form. [mime:%v] [e:%v]", mime, err)
+	}
+}
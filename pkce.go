@@ -0,0 +1,77 @@
+// A facebook graph api client in go.
+// https://github.com/huandu/facebook/
+//
+// Copyright 2012, Huan Du
+// Licensed under the MIT license
+// https://github.com/huandu/facebook/blob/master/LICENSE
+
+package facebook
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+)
+
+// CodeChallengeMethodS256 is the only PKCE code challenge method supported by this package.
+const CodeChallengeMethodS256 = "S256"
+
+// pkceVerifierLength is the number of random bytes in a code verifier.
+// 32 bytes are encoded to a 43 characters string, which is the minimum length allowed by RFC 7636.
+const pkceVerifierLength = 32
+
+// PKCE is a code verifier and its challenge used in the Proof Key for Code Exchange flow.
+//
+// Facebook document: https://developers.facebook.com/docs/facebook-login/guides/advanced/oidc-token/
+type PKCE struct {
+	Verifier        string // code_verifier sent when redeeming code.
+	Challenge       string // code_challenge sent in login dialog URL.
+	ChallengeMethod string // code_challenge_method. It's always CodeChallengeMethodS256.
+}
+
+// NewPKCE generates a random code verifier and its S256 challenge.
+//
+// Set the PKCE in LoginOptions to build a login dialog URL and
+// keep the Verifier to redeem code by ParseCodeWithVerifier.
+func (app *App) NewPKCE() (*PKCE, error) {
+	buf := make([]byte, pkceVerifierLength)
+
+	if _, err := rand.Read(buf); err != nil {
+		return nil, fmt.Errorf("facebook: cannot generate random code verifier; %w", err)
+	}
+
+	verifier := base64.RawURLEncoding.EncodeToString(buf)
+	return &PKCE{
+		Verifier:        verifier,
+		Challenge:       CodeChallengeS256(verifier),
+		ChallengeMethod: CodeChallengeMethodS256,
+	}, nil
+}
+
+// CodeChallengeS256 calculates the S256 code challenge of a code verifier.
+func CodeChallengeS256(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// ParseCodeWithVerifier redeems code for a valid access token with a PKCE code verifier.
+// It's a shorthand call to ParseCodeInfoWithVerifier(code, "", codeVerifier).
+func (app *App) ParseCodeWithVerifier(code, codeVerifier string) (token string, err error) {
+	token, _, _, err = app.ParseCodeInfoWithVerifier(code, "", codeVerifier)
+	return
+}
+
+// ParseCodeInfoWithVerifier redeems code for access token with a PKCE code verifier
+// and returns extra information. The machineId is optional.
+//
+// If app.AppSecret is empty, client_secret is not sent so that
+// a public client can redeem code with code verifier only.
+func (app *App) ParseCodeInfoWithVerifier(code, machineID, codeVerifier string) (token string, expires int, newMachineID string, err error) {
+	if codeVerifier == "" {
+		err = fmt.Errorf("facebook: code verifier is empty")
+		return
+	}
+
+	return app.parseCodeInfo(code, machineID, codeVerifier)
+}
@@ -0,0 +1,76 @@
+// A facebook graph api client in go.
+// https://github.com/huandu/facebook/
+//
+// Copyright 2012, Huan Du
+// Licensed under the MIT license
+// https://github.com/huandu/facebook/blob/master/LICENSE
+
+package facebook
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestPKCE(t *testing.T) {
+	const verifier = "dBjftJeZ4CVP-mJ92K9r3UHzHOMknQ_8sY9gc7bjLX4"
+	const challenge = "PmkmSoKGLfJ1LRFdzTkoDYzCdxzK8_mv-u61aUqccxk"
+
+	if actual := CodeChallengeS256(verifier); actual != challenge {
+		t.Fatalf("invalid code challenge. [expected:%v] [actual:%v]", challenge, actual)
+	}
+
+	app := New("123", "")
+	pkce, err := app.NewPKCE()
+
+	if err != nil {
+		t.Fatalf("fail to create PKCE. [e:%v]", err)
+	}
+
+	if len(pkce.Verifier) != 43 || pkce.Challenge != CodeChallengeS256(pkce.Verifier) || pkce.ChallengeMethod != CodeChallengeMethodS256 {
+		t.Fatalf("invalid PKCE. [pkce:%v]", pkce)
+	}
+
+	loginURL, _ := url.Parse(app.LoginURL("state", nil, &LoginOptions{
+		PKCE: pkce,
+	}))
+	query := loginURL.Query()
+
+	if query.Get("code_challenge") != pkce.Challenge || query.Get("code_challenge_method") != "S256" {
+		t.Fatalf("login url must contain code challenge. [url:%v]", loginURL)
+	}
+
+	testMux := http.NewServeMux()
+	testMux.HandleFunc("/v3.0/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+
+		if r.Form.Get("code_verifier") != verifier || r.Form.Get("code") != "the-code" {
+			t.Errorf("invalid code exchange params. [form:%v]", r.Form)
+		}
+
+		if _, ok := r.Form["client_secret"]; ok {
+			t.Errorf("public client must not send client_secret. [form:%v]", r.Form)
+		}
+
+		w.Write([]byte(`{"access_token":"the-token","token_type":"bearer","expires_in":3600}`))
+	})
+
+	srv := httptest.NewServer(testMux)
+	defer srv.Close()
+
+	app.SetSession(&Session{
+		Version: "v3.0",
+		BaseURL: srv.URL + "/",
+	})
+	token, expires, _, err := app.ParseCodeInfoWithVerifier("the-code", "", verifier)
+
+	if err != nil || token != "the-token" || expires != 3600 {
+		t.Fatalf("fail to redeem code with verifier. [token:%v] [expires:%v] [e:%v]", token, expires, err)
+	}
+
+	if _, err := app.ParseCodeWithVerifier("the-code", ""); err == nil {
+		t.Fatalf("empty code verifier must be rejected.")
+	}
+}
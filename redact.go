@@ -0,0 +1,79 @@
+// A facebook graph api client in go.
+// https://github.com/huandu/facebook/
+//
+// Copyright 2012, Huan Du
+// Licensed under the MIT license
+// https://github.com/huandu/facebook/blob/master/LICENSE
+
+package facebook
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Redacted is the value replacing secrets in redacted strings.
+const Redacted = "[REDACTED]"
+
+// secret params and fields which must not be logged.
+var redactedKeys = []string{
+	"access_token",
+	"input_token",
+	"appsecret_proof",
+	"client_secret",
+	"fb_exchange_token",
+	"code_verifier",
+	"signed_request",
+}
+
+var (
+	regexpRedactQuery = regexp.MustCompile(`((?:^|[?&\s])(?:` + strings.Join(redactedKeys, "|") + `)=)[^&\s"';]*`)
+	regexpRedactJSON  = regexp.MustCompile(`("(?:` + strings.Join(redactedKeys, "|") + `)"\s*:\s*")[^"]*"`)
+	regexpRedactAuth  = regexp.MustCompile(`((?:Bearer|OAuth)\s+)[^\s"',]+`)
+)
+
+// Redact replaces secrets in s with Redacted.
+// It works with URLs, url-encoded or JSON bodies, Authorization headers and error messages.
+//
+// Secrets include access_token, input_token, appsecret_proof, client_secret,
+// fb_exchange_token, code_verifier and signed_request.
+func Redact(s string) string {
+	s = regexpRedactQuery.ReplaceAllString(s, "${1}"+Redacted)
+	s = regexpRedactJSON.ReplaceAllString(s, `${1}`+Redacted+`"`)
+	s = regexpRedactAuth.ReplaceAllString(s, "${1}"+Redacted)
+	return s
+}
+
+// RedactError returns an error whose message is redacted by Redact.
+// The returned error wraps err so that errors.Is and errors.As still work.
+// It returns err itself if there is nothing to redact.
+func RedactError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	msg := err.Error()
+	redacted := Redact(msg)
+
+	if redacted == msg {
+		return err
+	}
+
+	return &redactedError{
+		msg: redacted,
+		err: err,
+	}
+}
+
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string {
+	return e.msg
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,56 @@
+// A facebook graph api client in go.
+// https://github.com/huandu/facebook/
+//
+// Copyright 2012, Huan Du
+// Licensed under the MIT license
+// https://github.com/huandu/facebook/blob/master/LICENSE
+
+package facebook
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	cases := map[string]string{
+		"https://graph.facebook.com/me?access_token=abc&fields=id": "https://graph.facebook.com/me?access_token=[REDACTED]&fields=id",
+		"/debug_token?input_token=abc&access_token=1|2":            "/debug_token?input_token=[REDACTED]&access_token=[REDACTED]",
+		"appsecret_proof=abc&client_secret=def&code=ghi":           "appsecret_proof=[REDACTED]&client_secret=[REDACTED]&code=ghi",
+		`{"access_token":"abc","token_type":"bearer"}`:             `{"access_token":"[REDACTED]","token_type":"bearer"}`,
+		`{"fb_exchange_token": "abc"}`:                             `{"fb_exchange_token": "[REDACTED]"}`,
+		"Authorization: Bearer abc":                                "Authorization: Bearer [REDACTED]",
+		"Authorization: OAuth abc":                                 "Authorization: OAuth [REDACTED]",
+		"my_access_token=abc":                                      "my_access_token=abc",
+		"nothing to redact":                                        "nothing to redact",
+	}
+
+	for input, expected := range cases {
+		if actual := Redact(input); actual != expected {
+			t.Fatalf("invalid redacted string. [input:%v] [expected:%v] [actual:%v]", input, expected, actual)
+		}
+	}
+}
+
+func TestRedactError(t *testing.T) {
+	if RedactError(nil) != nil {
+		t.Fatalf("nil error must be nil.")
+	}
+
+	plain := errors.New("plain error")
+
+	if RedactError(plain) != plain {
+		t.Fatalf("error without secret must be returned as is.")
+	}
+
+	err := RedactError(fmt.Errorf("fail to get /me?access_token=abc; %w", plain))
+
+	if err.Error() != "fail to get /me?access_token=[REDACTED]; plain error" {
+		t.Fatalf("invalid redacted error. [e:%v]", err)
+	}
+
+	if !errors.Is(err, plain) {
+		t.Fatalf("redacted error must wrap original error.")
+	}
+}
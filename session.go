@@ -20,6 +20,9 @@ import (
 	"net/url"
 	"regexp"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // Graph API debug mode values.
@@ -57,19 +60,46 @@ type Session struct {
 	BaseURL           string // set to override API base URL - trailing slash is required, e.g. http://127.0.0.1:53453/
 	Instagram         bool   // set the session explicity to Instagram, see https://developers.facebook.com/docs/instagram-platform/instagram-api-with-instagram-login/migration-guide#step-2--update-your-code
 
-	accessToken string // facebook access token. can be empty.
-	app         *App
-	id          string
+	app *App
 
-	enableAppsecretProof   bool   // add "appsecret_proof" parameter in every facebook API call.
-	appsecretProof         string // pre-calculated "appsecret_proof" value.
-	useAuthorizationHeader bool   // pass accessToken in headers instead of query params
+	sharedState atomic.Value // *sessionState shared by shallow copies of this session. use state() to get it.
 
-	debug DebugMode // using facebook debugging api in every request.
+	tokenRefresher *tokenRefresher // refresh token before it expires.
+	tokenSource    TokenSource     // provide access token per request. can be nil.
+
+	enableAppsecretProof   bool // add "appsecret_proof" parameter in every facebook API call. guarded by state().mu.
+	useAuthorizationHeader bool // pass accessToken in headers instead of query params. guarded by state().mu.
+
+	debug DebugMode // using facebook debugging api in every request. guarded by state().mu.
+
+	logger       Logger       // log every http request. can be nil.
+	instrumenter Instrumenter // receive events of every http request. can be nil.
+
+	versionWatcher      *versionWatcher      // report version warnings. can be nil.
+	debugMessageWatcher *debugMessageWatcher // report debug messages. can be nil.
 
 	context context.Context // Session context.
 }
 
+// sessionState is the access token of a session.
+// It's shared by shallow copies of a session, e.g. sessions created by WithContext,
+// so that a token changed by token source or auto refresh is visible to all of them.
+type sessionState struct {
+	mu             sync.RWMutex
+	accessToken    string // facebook access token. can be empty.
+	token          *Token // metadata of access token. can be nil.
+	id             string
+	appsecretProof string // pre-calculated "appsecret_proof" value.
+}
+
+// credential is the access token and its appsecret proof used by a request.
+// It's resolved once per request so that params and headers always use the same token.
+type credential struct {
+	accessToken            string
+	appsecretProof         string
+	useAuthorizationHeader bool
+}
+
 // HttpClient is an interface to send http request.
 // This interface is designed to be compatible with type `*http.Client`.
 type HttpClient interface {
@@ -139,10 +169,20 @@ func (session *Session) Batch(batchParams Params, params ...Params) ([]Result, e
 //	res, err := session.Request(request)
 //	fmt.Println(res["gender"])  // get "male"
 func (session *Session) Request(request *http.Request) (res Result, err error) {
+	var cred credential
+
+	if cred, err = session.resolveCredential(); err != nil {
+		return
+	}
+
+	return session.request(request, cred)
+}
+
+func (session *Session) request(request *http.Request, cred credential) (res Result, err error) {
 	var response *http.Response
 	var data []byte
 
-	response, data, err = session.sendRequest(request)
+	response, data, err = session.sendRequest(request, cred)
 
 	if err != nil {
 		return
@@ -151,11 +191,14 @@ func (session *Session) Request(request *http.Request) (res Result, err error) {
 	res, err = MakeResult(data)
 	session.addDebugInfo(res, response)
 	session.addUsageInfo(res, response)
+	session.checkVersion(res, response)
+	session.reportDebugMessages(request.URL.Path, res)
 
 	if res != nil {
 		err = res.Err()
 	}
 
+	session.checkTokenError(err)
 	return
 }
 
@@ -165,12 +208,16 @@ func (session *Session) Request(request *http.Request) (res Result, err error) {
 //
 // It's a standard way to validate a facebook access token.
 func (session *Session) User() (id string, err error) {
-	if session.id != "" {
-		id = session.id
+	state := session.state()
+	state.mu.RLock()
+	id = state.id
+	state.mu.RUnlock()
+
+	if id != "" {
 		return
 	}
 
-	if session.accessToken == "" && session.HttpClient == nil {
+	if session.AccessToken() == "" && session.tokenSource == nil && session.HttpClient == nil {
 		err = fmt.Errorf("facebook: access token is not set")
 		return
 	}
@@ -194,7 +241,7 @@ func (session *Session) User() (id string, err error) {
 // Validate validates Session access token.
 // Returns nil if access token is valid.
 func (session *Session) Validate() (err error) {
-	if session.accessToken == "" && session.HttpClient == nil {
+	if session.AccessToken() == "" && session.tokenSource == nil && session.HttpClient == nil {
 		err = fmt.Errorf("facebook: access token is not set")
 		return
 	}
@@ -207,7 +254,7 @@ func (session *Session) Validate() (err error) {
 	}
 
 	if f := result.Get("id"); f == nil {
-		err = fmt.Errorf("facebook: invalid access token %s", session.accessToken)
+		err = fmt.Errorf("facebook: invalid access token %s", session.AccessToken())
 		return
 	}
 
@@ -218,7 +265,17 @@ func (session *Session) Validate() (err error) {
 // Returns JSON array containing data about the inspected token.
 // See https://developers.facebook.com/docs/facebook-login/manually-build-a-login-flow/#checktoken
 func (session *Session) Inspect() (result Result, err error) {
-	if session.accessToken == "" && session.HttpClient == nil {
+	result, err = session.inspect()
+
+	if err != nil {
+		return
+	}
+
+	return inspectData(result)
+}
+
+func (session *Session) inspect() (result Result, err error) {
+	if session.AccessToken() == "" && session.tokenSource == nil && session.HttpClient == nil {
 		err = fmt.Errorf("facebook: access token is not set")
 		return
 	}
@@ -235,15 +292,20 @@ func (session *Session) Inspect() (result Result, err error) {
 		return
 	}
 
-	result, err = session.Api("/debug_token", GET, Params{
-		"input_token":  session.accessToken,
-		"access_token": appAccessToken,
-	})
+	cred, err := session.resolveCredential()
 
 	if err != nil {
 		return
 	}
 
+	result, err = session.Api("/debug_token", GET, Params{
+		"input_token":  cred.accessToken,
+		"access_token": appAccessToken,
+	})
+	return
+}
+
+func inspectData(result Result) (data Result, err error) {
 	// facebook stores everything, including error, inside result["data"].
 	// make sure that result["data"] exists and doesn't contain error.
 	if _, ok := result["data"]; !ok {
@@ -251,49 +313,49 @@ func (session *Session) Inspect() (result Result, err error) {
 		return
 	}
 
-	var data Result
 	result.DecodeField("data", &data)
-	result = data
-	err = result.Err()
+	err = data.Err()
 	return
 }
 
 // AccessToken gets current access token.
 func (session *Session) AccessToken() string {
-	return session.accessToken
+	state := session.state()
+	state.mu.RLock()
+	defer state.mu.RUnlock()
+
+	return state.accessToken
 }
 
 // SetAccessToken sets a new access token.
 func (session *Session) SetAccessToken(token string) {
-	if token != session.accessToken {
-		session.id = ""
-		session.accessToken = token
-		session.appsecretProof = ""
+	state := session.state()
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	state.setAccessToken(token)
+}
+
+func (state *sessionState) setAccessToken(token string) {
+	if token != state.accessToken {
+		state.id = ""
+		state.accessToken = token
+		state.appsecretProof = ""
 	}
 }
 
 // UseAuthorizationHeader passes `access_token` in HTTP Authorization header instead of query string.
 func (session *Session) UseAuthorizationHeader() {
+	state := session.state()
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
 	session.useAuthorizationHeader = true
 }
 
 // AppsecretProof checks appsecret proof is enabled or not.
 func (session *Session) AppsecretProof() string {
-	if !session.enableAppsecretProof {
-		return ""
-	}
-
-	if session.accessToken == "" || session.app == nil {
-		return ""
-	}
-
-	if session.appsecretProof == "" {
-		hash := hmac.New(sha256.New, []byte(session.app.AppSecret))
-		hash.Write([]byte(session.accessToken))
-		session.appsecretProof = hex.EncodeToString(hash.Sum(nil))
-	}
-
-	return session.appsecretProof
+	return session.currentCredential().appsecretProof
 }
 
 // EnableAppsecretProof enables or disable appsecret proof status.
@@ -303,12 +365,16 @@ func (session *Session) EnableAppsecretProof(enabled bool) error {
 		return fmt.Errorf("facebook: cannot change appsecret proof status without an associated App")
 	}
 
+	state := session.state()
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
 	if session.enableAppsecretProof != enabled {
 		session.enableAppsecretProof = enabled
 
 		// reset pre-calculated proof here to give caller a way to do so in some rare case,
 		// e.g. associated app's secret is changed.
-		session.appsecretProof = ""
+		state.appsecretProof = ""
 	}
 
 	return nil
@@ -321,8 +387,13 @@ func (session *Session) App() *App {
 
 // Debug returns current debug mode.
 func (session *Session) Debug() DebugMode {
-	if session.debug != DEBUG_OFF {
-		return session.debug
+	state := session.state()
+	state.mu.RLock()
+	debug := session.debug
+	state.mu.RUnlock()
+
+	if debug != DEBUG_OFF {
+		return debug
 	}
 
 	return Debug
@@ -332,6 +403,10 @@ func (session *Session) Debug() DebugMode {
 // If per session debug mode is DEBUG_OFF, session will use global
 // Debug mode.
 func (session *Session) SetDebug(debug DebugMode) DebugMode {
+	state := session.state()
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
 	old := session.debug
 	session.debug = debug
 	return old
@@ -351,7 +426,11 @@ func (session *Session) graph(path string, method Method, params Params) (res Re
 		params["date_format"] = `Y-m-d\TH:i:sP`
 	}
 
-	session.prepareParams(params)
+	var cred credential
+
+	if cred, err = session.prepareParams(params); err != nil {
+		return
+	}
 
 	// parse path only if path contains '?'.
 	// url.ParseRequestURI cannot parse uri without "/" like "me".
@@ -402,24 +481,28 @@ func (session *Session) graph(path string, method Method, params Params) (res Re
 	var response *http.Response
 
 	if method == GET {
-		response, err = session.sendGetRequest(graphURL, &res)
+		response, err = session.sendGetRequest(graphURL, cred, &res)
 	} else {
 		if method != POST {
 			params["method"] = method
 		}
 
-		response, err = session.sendPostRequest(graphURL, params, &res)
+		response, err = session.sendPostRequest(graphURL, params, cred, &res)
 	}
 
 	if response != nil {
 		session.addDebugInfo(res, response)
 		session.addUsageInfo(res, response)
+		session.checkVersion(res, response)
 	}
 
+	session.reportDebugMessages(path, res)
+
 	if res != nil {
 		err = res.Err()
 	}
 
+	session.checkTokenError(err)
 	return
 }
 
@@ -429,24 +512,44 @@ func (session *Session) graphBatch(batchParams Params, params ...Params) ([]Resu
 	}
 
 	batchParams["batch"] = params
-	session.prepareParams(batchParams)
+
+	cred, err := session.prepareParams(batchParams)
+
+	if err != nil {
+		return nil, err
+	}
 
 	var res []Result
+	var calls []batchCall
+
+	if session.instrumenter != nil {
+		calls = session.startBatch(session.Context(), params)
+	}
+
+	start := time.Now()
 	graphURL := session.getURL("graph", "", nil)
-	_, err := session.sendPostRequest(graphURL, batchParams, &res)
+	_, err = session.sendPostRequest(graphURL, batchParams, cred, &res)
+
+	if session.instrumenter != nil {
+		session.endBatch(calls, start, res, err)
+	}
+
 	return res, err
 }
 
-func (session *Session) prepareParams(params Params) {
+func (session *Session) prepareParams(params Params) (cred credential, err error) {
+	if cred, err = session.resolveCredential(); err != nil {
+		return
+	}
 
-	if !session.useAuthorizationHeader {
-		if _, ok := params["access_token"]; !ok && session.accessToken != "" {
-			params["access_token"] = session.accessToken
+	if !cred.useAuthorizationHeader {
+		if _, ok := params["access_token"]; !ok && cred.accessToken != "" {
+			params["access_token"] = cred.accessToken
 		}
 	}
 
-	if session.enableAppsecretProof && session.accessToken != "" && session.app != nil {
-		params["appsecret_proof"] = session.AppsecretProof()
+	if cred.appsecretProof != "" {
+		params["appsecret_proof"] = cred.appsecretProof
 	}
 
 	debug := session.Debug()
@@ -454,16 +557,108 @@ func (session *Session) prepareParams(params Params) {
 	if debug != DEBUG_OFF {
 		params["debug"] = debug
 	}
+
+	return
+}
+
+// resolveCredential gets access token from token source or refreshes current token if necessary,
+// and returns the token with its appsecret proof.
+func (session *Session) resolveCredential() (cred credential, err error) {
+	var token *Token
+
+	if session.tokenSource != nil {
+		if token, err = session.getSourceToken(); err != nil {
+			return
+		}
+	} else {
+		session.refreshToken()
+	}
+
+	state := session.state()
+	state.mu.RLock()
+	changed := token != nil && (token != state.token || token.Value != state.accessToken)
+	cred, ok := session.loadCredential(state, false)
+	state.mu.RUnlock()
+
+	if ok && !changed {
+		return
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	// keep the last token provided by token source so that Token and AccessToken can report it.
+	if token != nil && (token != state.token || token.Value != state.accessToken) {
+		state.setToken(token)
+	}
+
+	cred, _ = session.loadCredential(state, true)
+	return
 }
 
-func (session *Session) sendGetRequest(uri string, res interface{}) (*http.Response, error) {
+// currentCredential returns current access token without consulting token source.
+func (session *Session) currentCredential() credential {
+	state := session.state()
+	state.mu.RLock()
+	cred, ok := session.loadCredential(state, false)
+	state.mu.RUnlock()
+
+	if ok {
+		return cred
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	cred, _ = session.loadCredential(state, true)
+	return cred
+}
+
+// loadCredential returns current credential. The state.mu must be held by caller.
+// If appsecret proof is not pre-calculated, it's calculated only if calculate is true,
+// which requires a write lock. Otherwise, loadCredential returns false.
+func (session *Session) loadCredential(state *sessionState, calculate bool) (cred credential, ok bool) {
+	cred = credential{
+		accessToken:            state.accessToken,
+		useAuthorizationHeader: session.useAuthorizationHeader,
+	}
+
+	if !session.enableAppsecretProof || state.accessToken == "" || session.app == nil {
+		return cred, true
+	}
+
+	if state.appsecretProof == "" {
+		if !calculate {
+			return cred, false
+		}
+
+		hash := hmac.New(sha256.New, []byte(session.app.AppSecret))
+		hash.Write([]byte(state.accessToken))
+		state.appsecretProof = hex.EncodeToString(hash.Sum(nil))
+	}
+
+	cred.appsecretProof = state.appsecretProof
+	return cred, true
+}
+
+// state returns the token state shared by this session and its shallow copies.
+func (session *Session) state() *sessionState {
+	if state, ok := session.sharedState.Load().(*sessionState); ok {
+		return state
+	}
+
+	session.sharedState.CompareAndSwap(nil, &sessionState{})
+	return session.sharedState.Load().(*sessionState)
+}
+
+func (session *Session) sendGetRequest(uri string, cred credential, res interface{}) (*http.Response, error) {
 	request, err := http.NewRequest("GET", uri, nil)
 
 	if err != nil {
 		return nil, err
 	}
 
-	response, data, err := session.sendRequest(request)
+	response, data, err := session.sendRequest(request, cred)
 
 	if err != nil {
 		return response, err
@@ -473,7 +668,7 @@ func (session *Session) sendGetRequest(uri string, res interface{}) (*http.Respo
 	return response, err
 }
 
-func (session *Session) sendPostRequest(uri string, params Params, res interface{}) (*http.Response, error) {
+func (session *Session) sendPostRequest(uri string, params Params, cred credential, res interface{}) (*http.Response, error) {
 	buf := &bytes.Buffer{}
 	mime, err := params.Encode(buf)
 
@@ -488,7 +683,7 @@ func (session *Session) sendPostRequest(uri string, params Params, res interface
 	}
 
 	request.Header.Set("Content-Type", mime)
-	response, data, err := session.sendRequest(request)
+	response, data, err := session.sendRequest(request, cred)
 
 	if err != nil {
 		return response, err
@@ -514,7 +709,7 @@ func (session *Session) sendOauthRequest(uri string, params Params) (Result, err
 	}
 
 	request.Header.Set("Content-Type", mime)
-	_, data, err := session.sendRequest(request)
+	_, data, err := session.sendRequest(request, session.currentCredential())
 
 	if err != nil {
 		return nil, err
@@ -548,13 +743,31 @@ func (session *Session) sendOauthRequest(uri string, params Params) (Result, err
 	return res, err
 }
 
-func (session *Session) sendRequest(request *http.Request) (response *http.Response, data []byte, err error) {
+func (session *Session) sendRequest(request *http.Request, cred credential) (response *http.Response, data []byte, err error) {
 	if session.context != nil {
 		request = request.WithContext(session.context)
 	}
 
-	if session.useAuthorizationHeader {
-		request.Header.Set("Authorization", "Bearer "+session.accessToken)
+	// keep the Authorization header set by caller, e.g. the "OAuth" scheme required by upload api.
+	if cred.useAuthorizationHeader && request.Header.Get("Authorization") == "" {
+		request.Header.Set("Authorization", "Bearer "+cred.accessToken)
+	}
+
+	if session.instrumenter != nil {
+		var call *CallInfo
+		request, call = session.startCall(request)
+		start := time.Now()
+		defer func() {
+			session.endCall(request, call, start, response, data, err)
+		}()
+	}
+
+	if session.logger != nil {
+		entry := newLogEntry(request)
+		start := time.Now()
+		defer func() {
+			session.logCall(entry, start, response, data, err)
+		}()
 	}
 
 	if session.HttpClient == nil {
@@ -571,20 +784,11 @@ func (session *Session) sendRequest(request *http.Request) (response *http.Respo
 		if !ok || netUrlErr.URL == "" {
 			return
 		}
-		q := request.URL.Query()
-		redacted := false
-		for _, key := range []string{"access_token", "input_token"} {
-			if q.Has(key) {
-				q.Del(key)
-				redacted = true
-			}
-		}
-		if !redacted {
+		redacted := Redact(netUrlErr.URL)
+		if redacted == netUrlErr.URL {
 			return
 		}
-		url := *request.URL
-		url.RawQuery = q.Encode()
-		netUrlErr.URL = url.String()
+		netUrlErr.URL = redacted
 		err = fmt.Errorf("facebook: cannot reach facebook server; %w", netUrlErr)
 		return
 	}
@@ -706,8 +910,15 @@ func (session *Session) Context() context.Context {
 
 // WithContext returns a shallow copy of session with its context changed to ctx.
 // The provided ctx must be non-nil.
+//
+// The copy shares access token with session. A token changed by any of them,
+// e.g. refreshed by auto refresh, is used by all of them.
 func (session *Session) WithContext(ctx context.Context) *Session {
+	state := session.state()
+	state.mu.RLock()
 	s := *session
+	state.mu.RUnlock()
+
 	s.context = ctx
 	return &s
 }
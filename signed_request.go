@@ -0,0 +1,163 @@
+// A facebook graph api client in go.
+// https://github.com/huandu/facebook/
+//
+// Copyright 2012, Huan Du
+// Licensed under the MIT license
+// https://github.com/huandu/facebook/blob/master/LICENSE
+
+package facebook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const signedRequestAlgorithm = "HMAC-SHA256"
+
+// DefaultSignedRequestClockSkew is the default allowed clock skew in DecodeSignedRequest.
+const DefaultSignedRequestClockSkew = 30 * time.Second
+
+// SignedRequest is the typed payload of a signed request.
+//
+// Facebook document: https://developers.facebook.com/docs/reference/login/signed-request
+type SignedRequest struct {
+	Algorithm  string            // signature algorithm. It's always "HMAC-SHA256".
+	UserID     string            // app-scoped id of the user. It's empty if user doesn't authorize the app.
+	OAuthToken string            // access token of the user.
+	Code       string            // code which can be redeemed for an access token.
+	IssuedAt   time.Time         // zero if it's absent.
+	Expires    time.Time         // expire time of OAuthToken. zero if it's absent or token never expires.
+	User       SignedRequestUser // information about the user.
+	Payload    Result            // the full payload.
+}
+
+// SignedRequestUser is the user information in a signed request.
+type SignedRequestUser struct {
+	Country string
+	Locale  string
+	Age     SignedRequestUserAge
+}
+
+// SignedRequestUserAge is the age range of a user in a signed request.
+// Max is 0 if there is no upper bound.
+type SignedRequestUserAge struct {
+	Min int
+	Max int
+}
+
+type signedRequestData struct {
+	Algorithm  string
+	UserID     string `facebook:"user_id"`
+	OAuthToken string `facebook:"oauth_token"`
+	Code       string
+	IssuedAt   Int64
+	Expires    Int64
+	User       SignedRequestUser
+}
+
+// MakeSignedRequest signs payload with AppSecret and returns a signed request
+// which can be parsed by ParseSignedRequest.
+// It's useful to issue signed requests in server side or to make signed requests in tests.
+//
+// If "algorithm" is not set in payload, it's set to "HMAC-SHA256".
+// Only "HMAC-SHA256" is supported.
+//
+// Returns error if AppSecret is empty, as anyone can sign a request without secret.
+func (app *App) MakeSignedRequest(payload Result) (string, error) {
+	if app.AppSecret == "" {
+		return "", fmt.Errorf("facebook: cannot sign request without app secret")
+	}
+
+	data := make(Result, len(payload)+1)
+
+	for k, v := range payload {
+		data[k] = v
+	}
+
+	if algorithm, ok := data["algorithm"]; !ok {
+		data["algorithm"] = signedRequestAlgorithm
+	} else if algorithm != signedRequestAlgorithm {
+		return "", fmt.Errorf("facebook: signed request algorithm must be '%v'; actual is '%v'", signedRequestAlgorithm, algorithm)
+	}
+
+	jsonBytes, err := json.Marshal(data)
+
+	if err != nil {
+		return "", fmt.Errorf("facebook: fail to marshal signed request payload; %w", err)
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(jsonBytes)
+	hash := hmac.New(sha256.New, []byte(app.AppSecret))
+	hash.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(hash.Sum(nil)) + "." + encoded, nil
+}
+
+// DecodeSignedRequest parses signed request and returns the typed payload.
+//
+// Unlike ParseSignedRequest, it also validates issued_at and expires in the payload.
+// A signed request is rejected if its oauth_token has expired, it's issued in the future
+// or it's older than app.SignedRequestMaxAge.
+// All checks allow app.SignedRequestClockSkew difference between clocks.
+func (app *App) DecodeSignedRequest(signedRequest string) (*SignedRequest, error) {
+	res, err := app.ParseSignedRequest(signedRequest)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var data signedRequestData
+
+	if err = res.Decode(&data); err != nil {
+		return nil, fmt.Errorf("facebook: fail to decode signed request payload; %w", err)
+	}
+
+	sr := &SignedRequest{
+		Algorithm:  data.Algorithm,
+		UserID:     data.UserID,
+		OAuthToken: data.OAuthToken,
+		Code:       data.Code,
+		IssuedAt:   unixTime(data.IssuedAt),
+		Expires:    unixTime(data.Expires),
+		User:       data.User,
+		Payload:    res,
+	}
+
+	if err = app.validateSignedRequestTime(sr); err != nil {
+		return nil, err
+	}
+
+	return sr, nil
+}
+
+func (app *App) validateSignedRequestTime(sr *SignedRequest) error {
+	now := time.Now()
+	skew := app.SignedRequestClockSkew
+
+	if skew == 0 {
+		skew = DefaultSignedRequestClockSkew
+	} else if skew < 0 {
+		skew = 0
+	}
+
+	if !sr.Expires.IsZero() && now.After(sr.Expires.Add(skew)) {
+		return fmt.Errorf("facebook: signed request is expired at %v", sr.Expires)
+	}
+
+	if sr.IssuedAt.IsZero() {
+		return nil
+	}
+
+	if sr.IssuedAt.After(now.Add(skew)) {
+		return fmt.Errorf("facebook: signed request is issued in the future at %v", sr.IssuedAt)
+	}
+
+	if app.SignedRequestMaxAge > 0 && now.Sub(sr.IssuedAt) > app.SignedRequestMaxAge+skew {
+		return fmt.Errorf("facebook: signed request issued at %v is too old", sr.IssuedAt)
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+// A facebook graph api client in go.
+// https://github.com/huandu/facebook/
+//
+// Copyright 2012, Huan Du
+// Licensed under the MIT license
+// https://github.com/huandu/facebook/blob/master/LICENSE
+
+package facebook
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMakeSignedRequest(t *testing.T) {
+	app := New("123", "secret")
+	now := time.Now().Unix()
+	signedRequest, err := app.MakeSignedRequest(Result{
+		"user_id":     "456",
+		"oauth_token": "token",
+		"issued_at":   now,
+		"expires":     now + 3600,
+		"user": Result{
+			"country": "us",
+			"locale":  "en_US",
+			"age":     Result{"min": 21},
+		},
+	})
+
+	if err != nil {
+		t.Fatalf("fail to make signed request. [e:%v]", err)
+	}
+
+	sr, err := app.DecodeSignedRequest(signedRequest)
+
+	if err != nil {
+		t.Fatalf("fail to decode signed request. [e:%v]", err)
+	}
+
+	if sr.Algorithm != "HMAC-SHA256" || sr.UserID != "456" || sr.OAuthToken != "token" {
+		t.Fatalf("invalid signed request. [sr:%#v]", sr)
+	}
+
+	if sr.IssuedAt.Unix() != now || sr.Expires.Unix() != now+3600 {
+		t.Fatalf("invalid time in signed request. [issued_at:%v] [expires:%v]", sr.IssuedAt, sr.Expires)
+	}
+
+	if sr.User.Country != "us" || sr.User.Locale != "en_US" || sr.User.Age.Min != 21 || sr.User.Age.Max != 0 {
+		t.Fatalf("invalid user in signed request. [user:%#v]", sr.User)
+	}
+
+	if _, err := New("123", "other").DecodeSignedRequest(signedRequest); err == nil {
+		t.Fatalf("signed request must be rejected with a wrong secret.")
+	}
+
+	if _, err := app.MakeSignedRequest(Result{"algorithm": "HMAC-SHA1"}); err == nil {
+		t.Fatalf("unsupported algorithm must be rejected.")
+	}
+
+	if _, err := New("123", "").MakeSignedRequest(Result{"user_id": "456"}); err == nil {
+		t.Fatalf("signed request must not be signed without app secret.")
+	}
+}
+
+func TestDecodeSignedRequestFreshness(t *testing.T) {
+	app := New("123", "secret")
+	now := time.Now().Unix()
+	sign := func(payload Result) string {
+		signedRequest, err := app.MakeSignedRequest(payload)
+
+		if err != nil {
+			t.Fatalf("fail to make signed request. [e:%v]", err)
+		}
+
+		return signedRequest
+	}
+
+	expired := sign(Result{"issued_at": now - 600, "expires": now - 60})
+	future := sign(Result{"issued_at": now + 60})
+	old := sign(Result{"issued_at": now - 600})
+
+	if _, err := app.DecodeSignedRequest(expired); err == nil {
+		t.Fatalf("expired signed request must be rejected.")
+	}
+
+	if _, err := app.DecodeSignedRequest(future); err == nil {
+		t.Fatalf("signed request issued in the future must be rejected.")
+	}
+
+	// small clock difference is allowed by default.
+	ahead := sign(Result{"issued_at": now + 2})
+
+	if _, err := app.DecodeSignedRequest(ahead); err != nil {
+		t.Fatalf("signed request issued slightly ahead must be accepted. [e:%v]", err)
+	}
+
+	app.SignedRequestClockSkew = -1
+
+	if _, err := app.DecodeSignedRequest(ahead); err == nil {
+		t.Fatalf("negative clock skew must disallow any skew.")
+	}
+
+	app.SignedRequestClockSkew = 0
+
+	if _, err := app.DecodeSignedRequest(old); err != nil {
+		t.Fatalf("max age is not set and old signed request must be accepted. [e:%v]", err)
+	}
+
+	app.SignedRequestMaxAge = 5 * time.Minute
+
+	if _, err := app.DecodeSignedRequest(old); err == nil {
+		t.Fatalf("signed request older than max age must be rejected.")
+	}
+
+	app.SignedRequestClockSkew = 10 * time.Minute
+
+	for _, signedRequest := range []string{expired, future, old} {
+		if _, err := app.DecodeSignedRequest(signedRequest); err != nil {
+			t.Fatalf("signed request must be accepted with clock skew. [e:%v]", err)
+		}
+	}
+}
@@ -0,0 +1,356 @@
+// A facebook graph api client in go.
+// https://github.com/huandu/facebook/
+//
+// Copyright 2012, Huan Du
+// Licensed under the MIT license
+// https://github.com/huandu/facebook/blob/master/LICENSE
+
+package facebook
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Facebook graph api error codes for invalid access token.
+const (
+	ErrCodeInvalidSession = 102
+	ErrCodeInvalidToken   = 190
+)
+
+// tokenRefreshRetryInterval is the min interval between two failed token refresh attempts.
+const tokenRefreshRetryInterval = time.Minute
+
+// Token events emitted by Session.
+const (
+	TokenRefreshed     TokenEventType = iota + 1 // token is exchanged for a long-lived token.
+	TokenRefreshFailed                           // token exchange fails. Session keeps using the old token.
+	TokenInvalid                                 // facebook reports that token is invalid or expired.
+)
+
+// Token is an access token with its metadata.
+//
+// Zero time in IssuedAt, ExpiresAt or DataAccessExpiresAt means the time is unknown
+// or the token never expires.
+type Token struct {
+	Value               string    `json:"access_token"`
+	Type                string    `json:"token_type,omitempty"`
+	IssuedAt            time.Time `json:"issued_at,omitempty"`
+	ExpiresAt           time.Time `json:"expires_at,omitempty"`
+	DataAccessExpiresAt time.Time `json:"data_access_expires_at,omitempty"`
+	Scopes              []string  `json:"scopes,omitempty"`
+}
+
+// TokenEventType is the type of a TokenEvent.
+type TokenEventType int
+
+// TokenEvent is emitted by Session when its token is refreshed or found invalid.
+type TokenEvent struct {
+	Type     TokenEventType
+	Token    *Token // the token before event.
+	NewToken *Token // the new token. It's only set in TokenRefreshed event.
+	Err      error  // the error in TokenRefreshFailed and TokenInvalid event.
+}
+
+// TokenCallback is called when Session emits a TokenEvent.
+type TokenCallback func(session *Session, event *TokenEvent)
+
+type tokenRefresher struct {
+	mu          sync.Mutex
+	threshold   time.Duration
+	callback    TokenCallback
+	lastAttempt time.Time
+}
+
+// newTokenFromResult creates a token from oauth api result.
+func newTokenFromResult(res Result) (*Token, error) {
+	token := &Token{
+		IssuedAt: time.Now(),
+	}
+
+	if err := res.DecodeField("access_token", &token.Value); err != nil {
+		return nil, err
+	}
+
+	if _, ok := res["token_type"]; ok {
+		res.DecodeField("token_type", &token.Type)
+	}
+
+	expiresKey := "expires_in"
+
+	if _, ok := res["expires"]; ok {
+		expiresKey = "expires"
+	}
+
+	if _, ok := res[expiresKey]; ok {
+		var expires Int64
+
+		if err := res.DecodeField(expiresKey, &expires); err != nil {
+			return nil, err
+		}
+
+		if expires > 0 {
+			token.ExpiresAt = token.IssuedAt.Add(time.Duration(expires) * time.Second)
+		}
+	}
+
+	if _, ok := res["data_access_expiration_time"]; ok {
+		var expires Int64
+
+		if err := res.DecodeField("data_access_expiration_time", &expires); err != nil {
+			return nil, err
+		}
+
+		if expires > 0 {
+			token.DataAccessExpiresAt = time.Unix(int64(expires), 0)
+		}
+	}
+
+	return token, nil
+}
+
+// newTokenFromSignedRequest creates a token from oauth_token, issued_at and expires in signed request.
+func newTokenFromSignedRequest(res Result, value string) *Token {
+	token := &Token{
+		Value: value,
+	}
+	var issuedAt, expires Int64
+
+	if res.DecodeField("issued_at", &issuedAt) == nil && issuedAt > 0 {
+		token.IssuedAt = time.Unix(int64(issuedAt), 0)
+	}
+
+	if res.DecodeField("expires", &expires) == nil && expires > 0 {
+		token.ExpiresAt = time.Unix(int64(expires), 0)
+	}
+
+	return token
+}
+
+// Expired checks whether token is expired.
+// A token without ExpiresAt never expires.
+func (token *Token) Expired() bool {
+	return token.ExpiresWithin(0)
+}
+
+// ExpiresWithin checks whether token expires in d.
+// A token without ExpiresAt never expires.
+func (token *Token) ExpiresWithin(d time.Duration) bool {
+	if token.ExpiresAt.IsZero() {
+		return false
+	}
+
+	return time.Until(token.ExpiresAt) <= d
+}
+
+// Valid checks whether token is set and not expired.
+func (token *Token) Valid() bool {
+	return token != nil && token.Value != "" && !token.Expired()
+}
+
+// String returns token type and expire time without the token value.
+func (token *Token) String() string {
+	return fmt.Sprintf("Token{Type: %v, ExpiresAt: %v}", token.Type, token.ExpiresAt)
+}
+
+// ParseCodeToken redeems code for a Token.
+// It works like ParseCodeInfo except that it returns token metadata.
+func (app *App) ParseCodeToken(code string) (*Token, error) {
+	if code == "" {
+		return nil, fmt.Errorf("facebook: code is empty")
+	}
+
+	res, err := app.redeemCode(code, "")
+
+	if err != nil {
+		return nil, err
+	}
+
+	return newTokenFromResult(res)
+}
+
+// ExchangeLongLivedToken exchanges a short-lived access token for a long-lived Token.
+// It works like ExchangeToken except that it returns token metadata.
+func (app *App) ExchangeLongLivedToken(accessToken string) (*Token, error) {
+	if accessToken == "" {
+		return nil, fmt.Errorf("facebook: short lived accessToken is empty")
+	}
+
+	res, err := app.exchangeToken(accessToken)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return newTokenFromResult(res)
+}
+
+// SessionFromToken creates a session with a Token.
+func (app *App) SessionFromToken(token *Token) *Session {
+	session := app.Session("")
+	session.SetToken(token)
+	return session
+}
+
+// Token returns current access token with its metadata.
+// If the token is set by SetAccessToken, only Value is set in the returned Token.
+//
+// Returns nil if access token is not set.
+func (session *Session) Token() *Token {
+	state := session.state()
+	state.mu.RLock()
+	defer state.mu.RUnlock()
+
+	if state.token != nil && state.token.Value == state.accessToken {
+		return state.token
+	}
+
+	if state.accessToken == "" {
+		return nil
+	}
+
+	return &Token{
+		Value: state.accessToken,
+	}
+}
+
+// SetToken sets a new access token with its metadata.
+func (session *Session) SetToken(token *Token) {
+	state := session.state()
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	state.setToken(token)
+}
+
+func (state *sessionState) setToken(token *Token) {
+	if token == nil {
+		state.setAccessToken("")
+		state.token = nil
+		return
+	}
+
+	state.setAccessToken(token.Value)
+	state.token = token
+}
+
+// EnableAutoRefresh enables or disables token auto refresh.
+//
+// If threshold is positive, session exchanges its token for a long-lived token through
+// App#ExchangeLongLivedToken when the token expires within threshold.
+// It only works with a Token which has ExpiresAt set, e.g. a session created by App#SessionFromToken.
+// The refreshed token is shared by shallow copies of session, e.g. sessions created by WithContext.
+//
+// Returns error if there is no App associated with this Session.
+func (session *Session) EnableAutoRefresh(threshold time.Duration) error {
+	if session.app == nil {
+		return fmt.Errorf("facebook: cannot refresh token without an associated App")
+	}
+
+	session.getTokenRefresher().threshold = threshold
+	return nil
+}
+
+// OnTokenEvent sets a callback which is called when token is refreshed or found invalid.
+func (session *Session) OnTokenEvent(callback TokenCallback) {
+	session.getTokenRefresher().callback = callback
+}
+
+func (session *Session) getTokenRefresher() *tokenRefresher {
+	if session.tokenRefresher == nil {
+		session.tokenRefresher = &tokenRefresher{}
+	}
+
+	return session.tokenRefresher
+}
+
+// refreshToken exchanges token for a long-lived token if it's about to expire.
+func (session *Session) refreshToken() {
+	refresher := session.tokenRefresher
+
+	// token source is responsible to refresh its tokens.
+	if refresher == nil || refresher.threshold <= 0 || session.app == nil || session.tokenSource != nil {
+		return
+	}
+
+	if event := session.doRefreshToken(refresher); event != nil {
+		session.emitTokenEvent(event)
+	}
+}
+
+func (session *Session) doRefreshToken(refresher *tokenRefresher) *TokenEvent {
+	refresher.mu.Lock()
+	defer refresher.mu.Unlock()
+
+	token := session.Token()
+
+	if token == nil || token.ExpiresAt.IsZero() || token.Expired() || !token.ExpiresWithin(refresher.threshold) {
+		return nil
+	}
+
+	if time.Since(refresher.lastAttempt) < tokenRefreshRetryInterval {
+		return nil
+	}
+
+	refresher.lastAttempt = time.Now()
+	newToken, err := session.app.ExchangeLongLivedToken(token.Value)
+
+	if err != nil {
+		return &TokenEvent{
+			Type:  TokenRefreshFailed,
+			Token: token,
+			Err:   err,
+		}
+	}
+
+	if newToken.Scopes == nil {
+		newToken.Scopes = token.Scopes
+	}
+
+	// swap token and its appsecret proof at once.
+	// in-flight requests keep using the old token resolved for them.
+	// don't overwrite a token set by others during the exchange.
+	state := session.state()
+	state.mu.Lock()
+	swapped := state.accessToken == token.Value
+
+	if swapped {
+		state.setToken(newToken)
+	}
+
+	state.mu.Unlock()
+
+	if !swapped {
+		return nil
+	}
+
+	return &TokenEvent{
+		Type:     TokenRefreshed,
+		Token:    token,
+		NewToken: newToken,
+	}
+}
+
+// checkTokenError emits TokenInvalid event if err is an invalid token error.
+func (session *Session) checkTokenError(err error) {
+	e, ok := err.(*Error)
+
+	if !ok || (e.Code != ErrCodeInvalidToken && e.Code != ErrCodeInvalidSession) {
+		return
+	}
+
+	session.emitTokenEvent(&TokenEvent{
+		Type:  TokenInvalid,
+		Token: session.Token(),
+		Err:   err,
+	})
+}
+
+func (session *Session) emitTokenEvent(event *TokenEvent) {
+	if session.tokenRefresher == nil || session.tokenRefresher.callback == nil {
+		return
+	}
+
+	session.tokenRefresher.callback(session, event)
+}
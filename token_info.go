@@ -0,0 +1,181 @@
+// A facebook graph api client in go.
+// https://github.com/huandu/facebook/
+//
+// Copyright 2012, Huan Du
+// Licensed under the MIT license
+// https://github.com/huandu/facebook/blob/master/LICENSE
+
+package facebook
+
+import (
+	"fmt"
+	"time"
+)
+
+// TokenInfo is the data about an access token returned by the debug_token api.
+//
+// Facebook document: https://developers.facebook.com/docs/graph-api/reference/debug_token
+type TokenInfo struct {
+	AppID               string          // id of the app which the token belongs to.
+	Type                string          // token type, e.g. "USER", "PAGE" or "APP".
+	Application         string          // name of the app.
+	UserID              string          // id of the user who the token belongs to.
+	ProfileID           string          // id of the page or instagram account for page tokens.
+	IsValid             bool            // whether the token is valid.
+	IssuedAt            time.Time       // zero if it's unknown.
+	ExpiresAt           time.Time       // zero if the token never expires.
+	DataAccessExpiresAt time.Time       // zero if it's unknown.
+	Scopes              []string        // permissions granted to the token.
+	GranularScopes      []GranularScope // permissions granted on specific targets.
+	Metadata            Result          // other metadata, e.g. "auth_type" and "sso".
+	Error               *Error          // the reason why the token is invalid. It's nil if token is valid.
+}
+
+// GranularScope is a permission granted on specific targets, e.g. pages or business assets.
+// An empty TargetIDs means the permission is granted on all targets.
+type GranularScope struct {
+	Scope     string
+	TargetIDs []string `facebook:"target_ids"`
+}
+
+type tokenInfoData struct {
+	AppID               string `facebook:"app_id"`
+	Type                string
+	Application         string
+	UserID              string `facebook:"user_id"`
+	ProfileID           string `facebook:"profile_id"`
+	IsValid             bool
+	IssuedAt            Int64
+	ExpiresAt           Int64
+	DataAccessExpiresAt Int64
+	Scopes              []string
+	GranularScopes      []GranularScope
+	Metadata            Result
+}
+
+func newTokenInfo(data Result) (*TokenInfo, error) {
+	var d tokenInfoData
+
+	if err := data.Decode(&d); err != nil {
+		return nil, fmt.Errorf("facebook: fail to decode token info; %w", err)
+	}
+
+	info := &TokenInfo{
+		AppID:               d.AppID,
+		Type:                d.Type,
+		Application:         d.Application,
+		UserID:              d.UserID,
+		ProfileID:           d.ProfileID,
+		IsValid:             d.IsValid,
+		IssuedAt:            unixTime(d.IssuedAt),
+		ExpiresAt:           unixTime(d.ExpiresAt),
+		DataAccessExpiresAt: unixTime(d.DataAccessExpiresAt),
+		Scopes:              d.Scopes,
+		GranularScopes:      d.GranularScopes,
+		Metadata:            d.Metadata,
+	}
+
+	if err, ok := data.Err().(*Error); ok {
+		info.Error = err
+	}
+
+	return info, nil
+}
+
+func unixTime(t Int64) time.Time {
+	if t <= 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(int64(t), 0)
+}
+
+// HasScope checks whether scope is granted.
+func (info *TokenInfo) HasScope(scope string) bool {
+	for _, s := range info.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HasGranularScope checks whether scope is granted on the target.
+func (info *TokenInfo) HasGranularScope(scope, targetID string) bool {
+	for _, gs := range info.GranularScopes {
+		if gs.Scope != scope {
+			continue
+		}
+
+		if len(gs.TargetIDs) == 0 {
+			return true
+		}
+
+		for _, id := range gs.TargetIDs {
+			if id == targetID {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// ExpiresWithin checks whether token expires in d.
+// A token without expire time never expires.
+func (info *TokenInfo) ExpiresWithin(d time.Duration) bool {
+	if info.ExpiresAt.IsZero() {
+		return false
+	}
+
+	return time.Until(info.ExpiresAt) <= d
+}
+
+// InspectToken inspects an access token with app access token.
+// Unlike Session#Inspect, it doesn't require a Session bound to the token.
+//
+// If facebook reports the token is invalid, InspectToken returns both
+// the TokenInfo and the reason as an *Error.
+func (app *App) InspectToken(accessToken string) (*TokenInfo, error) {
+	if accessToken == "" {
+		return nil, fmt.Errorf("facebook: access token is empty")
+	}
+
+	result, err := app.getSession().Get("/debug_token", Params{
+		"input_token":  accessToken,
+		"access_token": app.AppAccessToken(),
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return newTokenInfoFromData(inspectData(result))
+}
+
+// InspectToken inspects Session access token and returns typed TokenInfo.
+// See Session#Inspect for details.
+func (session *Session) InspectToken() (*TokenInfo, error) {
+	result, err := session.inspect()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return newTokenInfoFromData(inspectData(result))
+}
+
+func newTokenInfoFromData(data Result, err error) (*TokenInfo, error) {
+	if data == nil {
+		return nil, err
+	}
+
+	info, e := newTokenInfo(data)
+
+	if e != nil {
+		return nil, e
+	}
+
+	return info, err
+}
@@ -0,0 +1,110 @@
+// A facebook graph api client in go.
+// https://github.com/huandu/facebook/
+//
+// Copyright 2012, Huan Du
+// Licensed under the MIT license
+// https://github.com/huandu/facebook/blob/master/LICENSE
+
+package facebook
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAppInspectToken(t *testing.T) {
+	expiresAt := time.Now().Add(time.Hour).Unix()
+	testMux := http.NewServeMux()
+	testMux.HandleFunc("/v3.0/debug_token", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		if query.Get("access_token") != "123|secret" {
+			t.Errorf("app access token must be used. [query:%v]", query)
+		}
+
+		switch query.Get("input_token") {
+		case "valid":
+			fmt.Fprintf(w, `{"data":{
+				"app_id":"123",
+				"type":"USER",
+				"application":"Test App",
+				"data_access_expires_at":1600000000,
+				"expires_at":%v,
+				"is_valid":true,
+				"issued_at":1500000000,
+				"metadata":{"auth_type":"rerequest","sso":"ios"},
+				"scopes":["email","pages_show_list"],
+				"granular_scopes":[
+					{"scope":"pages_show_list","target_ids":["111","222"]},
+					{"scope":"email"}
+				],
+				"user_id":"456"
+			}}`, expiresAt)
+
+		default:
+			w.Write([]byte(`{"data":{
+				"app_id":"123",
+				"is_valid":false,
+				"expires_at":0,
+				"error":{"code":190,"message":"Invalid OAuth access token.","subcode":0},
+				"scopes":[]
+			}}`))
+		}
+	})
+
+	srv := httptest.NewServer(testMux)
+	defer srv.Close()
+
+	app := New("123", "secret")
+	app.SetSession(&Session{
+		Version: "v3.0",
+		BaseURL: srv.URL + "/",
+	})
+
+	info, err := app.InspectToken("valid")
+
+	if err != nil {
+		t.Fatalf("fail to inspect token. [e:%v]", err)
+	}
+
+	if info.AppID != "123" || info.UserID != "456" || info.Type != "USER" || !info.IsValid ||
+		info.ExpiresAt.Unix() != expiresAt || info.IssuedAt.Unix() != 1500000000 ||
+		info.DataAccessExpiresAt.Unix() != 1600000000 || info.Metadata["sso"] != "ios" || info.Error != nil {
+		t.Fatalf("invalid token info. [info:%#v]", info)
+	}
+
+	if !info.HasScope("email") || info.HasScope("publish_video") {
+		t.Fatalf("invalid scopes. [scopes:%v]", info.Scopes)
+	}
+
+	if !info.HasGranularScope("pages_show_list", "222") || info.HasGranularScope("pages_show_list", "333") ||
+		!info.HasGranularScope("email", "any") || info.HasGranularScope("ads_read", "111") {
+		t.Fatalf("invalid granular scopes. [granular_scopes:%v]", info.GranularScopes)
+	}
+
+	if !info.ExpiresWithin(2*time.Hour) || info.ExpiresWithin(time.Minute) {
+		t.Fatalf("token must expire in an hour. [expires_at:%v]", info.ExpiresAt)
+	}
+
+	info, err = app.InspectToken("invalid")
+
+	if err == nil || info == nil {
+		t.Fatalf("invalid token must return both info and error. [info:%v] [e:%v]", info, err)
+	}
+
+	if info.IsValid || info.Error == nil || info.Error.Code != ErrCodeInvalidToken || !info.ExpiresAt.IsZero() || info.ExpiresWithin(time.Hour) {
+		t.Fatalf("invalid token info. [info:%#v]", info)
+	}
+
+	session := app.Session("valid")
+	session.Version = "v3.0"
+	session.BaseURL = srv.URL + "/"
+	info, err = session.InspectToken()
+
+	if err != nil || info.UserID != "456" {
+		t.Fatalf("fail to inspect session token. [info:%v] [e:%v]", info, err)
+	}
+}
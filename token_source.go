@@ -0,0 +1,149 @@
+// A facebook graph api client in go.
+// https://github.com/huandu/facebook/
+//
+// Copyright 2012, Huan Du
+// Licensed under the MIT license
+// https://github.com/huandu/facebook/blob/master/LICENSE
+
+package facebook
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultTokenExpiryDelta is the default duration to treat a cached token as expired
+// before its ExpiresAt in CachingTokenSource.
+const DefaultTokenExpiryDelta = 10 * time.Second
+
+// TokenSource provides access tokens for Session.
+// It's designed to be similar to oauth2.TokenSource.
+//
+// Token may be called once per Graph API request.
+// Implementations should be safe for concurrent use and fast enough.
+// Wrap a slow TokenSource, e.g. one reading a secret store, with CachingTokenSource.
+type TokenSource interface {
+	Token() (*Token, error)
+}
+
+// TokenSourceFunc is an adapter to use a func as a TokenSource.
+type TokenSourceFunc func() (*Token, error)
+
+// Token calls f().
+func (f TokenSourceFunc) Token() (*Token, error) {
+	return f()
+}
+
+type staticTokenSource struct {
+	token *Token
+}
+
+// StaticTokenSource returns a TokenSource which always returns the same token.
+func StaticTokenSource(token *Token) TokenSource {
+	return staticTokenSource{
+		token: token,
+	}
+}
+
+func (s staticTokenSource) Token() (*Token, error) {
+	return s.token, nil
+}
+
+// CachingTokenSource caches the token returned by Source until the token expires
+// or it has been cached longer than TTL.
+type CachingTokenSource struct {
+	Source      TokenSource   // the underlying token source.
+	TTL         time.Duration // max duration to cache a token. A token is cached until it expires if TTL is 0.
+	ExpiryDelta time.Duration // refresh a token earlier than its ExpiresAt. DefaultTokenExpiryDelta is used if it's 0.
+
+	mu       sync.Mutex
+	token    *Token
+	cachedAt time.Time
+}
+
+var _ TokenSource = &CachingTokenSource{}
+
+// NewCachingTokenSource creates a CachingTokenSource wrapping src.
+func NewCachingTokenSource(src TokenSource, ttl time.Duration) *CachingTokenSource {
+	return &CachingTokenSource{
+		Source: src,
+		TTL:    ttl,
+	}
+}
+
+// Token returns the cached token if it's still valid.
+// Otherwise, it gets a new token from Source.
+func (s *CachingTokenSource) Token() (*Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != nil && !s.expired() {
+		return s.token, nil
+	}
+
+	token, err := s.Source.Token()
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.token = token
+	s.cachedAt = time.Now()
+	return token, nil
+}
+
+// Invalidate drops the cached token so that next call to Token gets a new token from Source.
+// It's useful when facebook reports the token is invalid. See Session#OnTokenEvent.
+func (s *CachingTokenSource) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.token = nil
+}
+
+func (s *CachingTokenSource) expired() bool {
+	if s.TTL > 0 && time.Since(s.cachedAt) >= s.TTL {
+		return true
+	}
+
+	delta := s.ExpiryDelta
+
+	if delta == 0 {
+		delta = DefaultTokenExpiryDelta
+	}
+
+	return s.token.ExpiresWithin(delta)
+}
+
+// SetTokenSource sets a TokenSource to provide access token per request.
+// Session consults the source before every request and recalculates
+// appsecret proof whenever the token changes.
+// A request always sends the token and its proof resolved for it,
+// even if other goroutines get new tokens from the source at the same time.
+//
+// Set source to nil to stop using token source.
+// The last token provided by the source is still used in this case.
+func (session *Session) SetTokenSource(source TokenSource) {
+	session.tokenSource = source
+}
+
+// TokenSource returns current TokenSource. It can be nil.
+func (session *Session) TokenSource() TokenSource {
+	return session.tokenSource
+}
+
+// getSourceToken gets current token from token source.
+func (session *Session) getSourceToken() (*Token, error) {
+	token, err := session.tokenSource.Token()
+
+	if err != nil {
+		return nil, fmt.Errorf("facebook: cannot get access token from token source; %w", err)
+	}
+
+	if token == nil || token.Value == "" {
+		return nil, fmt.Errorf("facebook: token source returns an empty access token")
+	}
+
+	return token, nil
+}
@@ -0,0 +1,223 @@
+// A facebook graph api client in go.
+// https://github.com/huandu/facebook/
+//
+// Copyright 2012, Huan Du
+// Licensed under the MIT license
+// https://github.com/huandu/facebook/blob/master/LICENSE
+
+package facebook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCachingTokenSource(t *testing.T) {
+	calls := 0
+	src := NewCachingTokenSource(TokenSourceFunc(func() (*Token, error) {
+		calls++
+		return &Token{
+			Value:     "token",
+			ExpiresAt: time.Now().Add(time.Hour),
+		}, nil
+	}), 0)
+
+	for i := 0; i < 3; i++ {
+		if token, err := src.Token(); err != nil || token.Value != "token" {
+			t.Fatalf("fail to get token. [token:%v] [e:%v]", token, err)
+		}
+	}
+
+	if calls != 1 {
+		t.Fatalf("token must be cached. [calls:%v]", calls)
+	}
+
+	src.Invalidate()
+	src.Token()
+
+	if calls != 2 {
+		t.Fatalf("token must be fetched again after invalidation. [calls:%v]", calls)
+	}
+
+	src.ExpiryDelta = 2 * time.Hour
+	src.Token()
+
+	if calls != 3 {
+		t.Fatalf("token expiring within expiry delta must be fetched again. [calls:%v]", calls)
+	}
+
+	src.ExpiryDelta = 0
+	src.TTL = time.Nanosecond
+	src.Token()
+
+	if calls != 4 {
+		t.Fatalf("token must be fetched again after ttl. [calls:%v]", calls)
+	}
+}
+
+func TestSessionTokenSource(t *testing.T) {
+	var proofs, tokens []string
+	testMux := http.NewServeMux()
+	testMux.HandleFunc("/v3.0/me", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		tokens = append(tokens, query.Get("access_token")+r.Header.Get("Authorization"))
+		proofs = append(proofs, query.Get("appsecret_proof"))
+		w.Write([]byte(`{"id":"123"}`))
+	})
+
+	srv := httptest.NewServer(testMux)
+	defer srv.Close()
+
+	app := New("123", "secret")
+	app.EnableAppsecretProof = true
+	session := app.Session("")
+	session.Version = "v3.0"
+	session.BaseURL = srv.URL + "/"
+
+	current := "token-1"
+	session.SetTokenSource(TokenSourceFunc(func() (*Token, error) {
+		if current == "" {
+			return nil, errors.New("token source is broken")
+		}
+
+		return &Token{Value: current}, nil
+	}))
+
+	session.Get("/me", nil)
+	current = "token-2"
+	session.Get("/me", nil)
+	session.UseAuthorizationHeader()
+	current = "token-3"
+	session.Get("/me", nil)
+
+	expected := []string{"token-1", "token-2", "Bearer token-3"}
+
+	for i, token := range expected {
+		if tokens[i] != token {
+			t.Fatalf("token source must be used per request. [expected:%v] [actual:%v]", expected, tokens)
+		}
+	}
+
+	if proofs[0] == "" || proofs[0] == proofs[1] || proofs[1] == proofs[2] {
+		t.Fatalf("appsecret proof must be recalculated when token changes. [proofs:%v]", proofs)
+	}
+
+	if session.AccessToken() != "token-3" {
+		t.Fatalf("session must keep the last token. [token:%v]", session.AccessToken())
+	}
+
+	current = ""
+
+	if _, err := session.Get("/me", nil); err == nil {
+		t.Fatalf("token source error must be returned.")
+	}
+
+	if len(tokens) != 3 {
+		t.Fatalf("request must not be sent when token source fails.")
+	}
+}
+
+// Run with -race to check token source is safe for concurrent requests.
+func TestSessionTokenSourceConcurrency(t *testing.T) {
+	app := New("123", "secret")
+	app.EnableAppsecretProof = true
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+		if token == "" {
+			token = r.URL.Query().Get("access_token")
+		}
+
+		hash := hmac.New(sha256.New, []byte(app.AppSecret))
+		hash.Write([]byte(token))
+
+		if proof := r.URL.Query().Get("appsecret_proof"); proof != hex.EncodeToString(hash.Sum(nil)) {
+			t.Errorf("appsecret proof must match access token. [token:%v] [proof:%v]", token, proof)
+		}
+
+		w.Write([]byte(`{"id":"123"}`))
+	}))
+	defer srv.Close()
+
+	for _, useHeader := range []bool{false, true} {
+		var counter int64
+		session := app.Session("")
+		session.BaseURL = srv.URL + "/"
+		session.SetTokenSource(TokenSourceFunc(func() (*Token, error) {
+			n := atomic.AddInt64(&counter, 1)
+			return &Token{Value: fmt.Sprintf("token-%v", n)}, nil
+		}))
+
+		if useHeader {
+			session.UseAuthorizationHeader()
+		}
+
+		wg := &sync.WaitGroup{}
+
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				for j := 0; j < 10; j++ {
+					if _, err := session.Get("/me", nil); err != nil {
+						t.Errorf("fail to get /me. [e:%v]", err)
+					}
+
+					session.AccessToken()
+					session.Token()
+				}
+			}()
+		}
+
+		wg.Wait()
+	}
+}
+
+func TestSessionStateLock(t *testing.T) {
+	app := New("123", "secret")
+	app.EnableAppsecretProof = true
+	session := app.Session("token")
+	other := app.Session("token")
+	copied := session.WithContext(context.Background())
+
+	if session.state() == other.state() || session.state() != copied.state() {
+		t.Fatalf("token state must be per session and shared by shallow copies.")
+	}
+
+	proof := session.AppsecretProof()
+
+	// read lock is enough when token doesn't change.
+	state := session.state()
+	state.mu.RLock()
+	done := make(chan credential)
+
+	go func() {
+		cred, _ := session.resolveCredential()
+		done <- cred
+	}()
+
+	select {
+	case cred := <-done:
+		if cred.accessToken != "token" || cred.appsecretProof != proof {
+			t.Errorf("invalid credential. [cred:%#v]", cred)
+		}
+
+	case <-time.After(time.Second):
+		t.Errorf("resolving an unchanged token must not take write lock.")
+	}
+
+	state.mu.RUnlock()
+}
@@ -0,0 +1,247 @@
+// A facebook graph api client in go.
+// https://github.com/huandu/facebook/
+//
+// Copyright 2012, Huan Du
+// Licensed under the MIT license
+// https://github.com/huandu/facebook/blob/master/LICENSE
+
+package facebook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTokenExpiry(t *testing.T) {
+	token := &Token{
+		Value: "token",
+	}
+
+	if !token.Valid() || token.Expired() || token.ExpiresWithin(time.Hour) {
+		t.Fatalf("token without expire time never expires.")
+	}
+
+	token.ExpiresAt = time.Now().Add(30 * time.Minute)
+
+	if !token.Valid() || !token.ExpiresWithin(time.Hour) || token.ExpiresWithin(time.Minute) {
+		t.Fatalf("token must expire in 30 minutes. [token:%v]", token)
+	}
+
+	token.ExpiresAt = time.Now().Add(-time.Minute)
+
+	if token.Valid() || !token.Expired() {
+		t.Fatalf("token must be expired. [token:%v]", token)
+	}
+
+	res := Result{
+		"access_token":                "token",
+		"token_type":                  "bearer",
+		"expires_in":                  "5183944",
+		"data_access_expiration_time": 1600000000,
+	}
+	token, err := newTokenFromResult(res)
+
+	if err != nil {
+		t.Fatalf("fail to create token from result. [e:%v]", err)
+	}
+
+	if token.Value != "token" || token.Type != "bearer" ||
+		token.ExpiresAt.Sub(token.IssuedAt) != 5183944*time.Second ||
+		token.DataAccessExpiresAt.Unix() != 1600000000 {
+		t.Fatalf("invalid token. [token:%#v]", token)
+	}
+}
+
+func TestSessionAutoRefreshToken(t *testing.T) {
+	exchanged := 0
+	testMux := http.NewServeMux()
+	testMux.HandleFunc("/v3.0/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+
+		if r.Form.Get("grant_type") != "fb_exchange_token" || r.Form.Get("fb_exchange_token") != "short-lived" {
+			t.Errorf("invalid token exchange params. [form:%v]", r.Form)
+		}
+
+		exchanged++
+		w.Write([]byte(`{"access_token":"long-lived","token_type":"bearer","expires_in":5184000}`))
+	})
+	testMux.HandleFunc("/v3.0/me", func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("access_token") {
+		case "long-lived":
+			w.Write([]byte(`{"id":"123"}`))
+		default:
+			w.Write([]byte(`{"error":{"message":"Error validating access token","type":"OAuthException","code":190}}`))
+		}
+	})
+
+	srv := httptest.NewServer(testMux)
+	defer srv.Close()
+
+	app := New("123", "secret")
+	app.SetSession(&Session{
+		Version: "v3.0",
+		BaseURL: srv.URL + "/",
+	})
+
+	session := app.SessionFromToken(&Token{
+		Value:     "short-lived",
+		Scopes:    []string{"email"},
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	session.Version = "v3.0"
+	session.BaseURL = srv.URL + "/"
+
+	var events []*TokenEvent
+	session.OnTokenEvent(func(s *Session, event *TokenEvent) {
+		events = append(events, event)
+	})
+
+	if err := session.EnableAutoRefresh(2 * time.Hour); err != nil {
+		t.Fatalf("fail to enable auto refresh. [e:%v]", err)
+	}
+
+	if _, err := session.Get("/me", nil); err != nil {
+		t.Fatalf("fail to get me with refreshed token. [e:%v]", err)
+	}
+
+	if exchanged != 1 || len(events) != 1 || events[0].Type != TokenRefreshed {
+		t.Fatalf("token must be refreshed once. [exchanged:%v] [events:%v]", exchanged, events)
+	}
+
+	token := session.Token()
+
+	if token.Value != "long-lived" || events[0].NewToken != token || len(token.Scopes) != 1 {
+		t.Fatalf("invalid refreshed token. [token:%#v]", token)
+	}
+
+	// long-lived token doesn't need to be refreshed.
+	session.Get("/me", nil)
+
+	if exchanged != 1 {
+		t.Fatalf("long-lived token must not be refreshed.")
+	}
+
+	session.SetAccessToken("invalid")
+	events = nil
+
+	if _, err := session.Get("/me", nil); err == nil {
+		t.Fatalf("invalid token must fail.")
+	}
+
+	if len(events) != 1 || events[0].Type != TokenInvalid || events[0].Token.Value != "invalid" || events[0].Err == nil {
+		t.Fatalf("invalid token event must be emitted. [events:%v]", events)
+	}
+}
+
+// Run with -race to check auto refresh is safe for concurrent requests.
+func TestSessionAutoRefreshTokenConcurrency(t *testing.T) {
+	var exchanged int64
+	app := New("123", "secret")
+	app.EnableAppsecretProof = true
+
+	testMux := http.NewServeMux()
+	testMux.HandleFunc("/v3.0/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&exchanged, 1)
+		w.Write([]byte(`{"access_token":"long-lived","token_type":"bearer","expires_in":5184000}`))
+	})
+	testMux.HandleFunc("/v3.0/me", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		hash := hmac.New(sha256.New, []byte(app.AppSecret))
+		hash.Write([]byte(query.Get("access_token")))
+
+		if proof := query.Get("appsecret_proof"); proof != hex.EncodeToString(hash.Sum(nil)) {
+			t.Errorf("appsecret proof must match access token. [query:%v]", query)
+		}
+
+		w.Write([]byte(`{"id":"123"}`))
+	})
+
+	srv := httptest.NewServer(testMux)
+	defer srv.Close()
+
+	app.SetSession(&Session{
+		Version: "v3.0",
+		BaseURL: srv.URL + "/",
+	})
+	session := app.SessionFromToken(&Token{
+		Value:     "short-lived",
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	session.Version = "v3.0"
+	session.BaseURL = srv.URL + "/"
+	session.EnableAutoRefresh(2 * time.Hour)
+
+	wg := &sync.WaitGroup{}
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 10; j++ {
+				if _, err := session.Get("/me", nil); err != nil {
+					t.Errorf("fail to get /me. [e:%v]", err)
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if n := atomic.LoadInt64(&exchanged); n != 1 || session.AccessToken() != "long-lived" {
+		t.Fatalf("token must be refreshed once. [exchanged:%v] [token:%v]", n, session.AccessToken())
+	}
+}
+
+func TestSessionAutoRefreshTokenWithContext(t *testing.T) {
+	exchanged := 0
+	testMux := http.NewServeMux()
+	testMux.HandleFunc("/v3.0/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+		exchanged++
+		w.Write([]byte(`{"access_token":"long-lived","token_type":"bearer","expires_in":5184000}`))
+	})
+	testMux.HandleFunc("/v3.0/me", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":"123"}`))
+	})
+
+	srv := httptest.NewServer(testMux)
+	defer srv.Close()
+
+	app := New("123", "secret")
+	app.SetSession(&Session{
+		Version: "v3.0",
+		BaseURL: srv.URL + "/",
+	})
+
+	session := app.SessionFromToken(&Token{
+		Value:     "short-lived",
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	session.Version = "v3.0"
+	session.BaseURL = srv.URL + "/"
+	session.EnableAutoRefresh(2 * time.Hour)
+	early := session.WithContext(context.Background())
+
+	if _, err := session.WithContext(context.Background()).Get("/me", nil); err != nil {
+		t.Fatalf("fail to get /me. [e:%v]", err)
+	}
+
+	if session.AccessToken() != "long-lived" || early.AccessToken() != "long-lived" {
+		t.Fatalf("token refreshed by a copy must be shared. [token:%v] [early:%v]", session.AccessToken(), early.AccessToken())
+	}
+
+	session.WithContext(context.Background()).Get("/me", nil)
+	early.Get("/me", nil)
+
+	if exchanged != 1 {
+		t.Fatalf("token must be refreshed once. [exchanged:%v]", exchanged)
+	}
+}
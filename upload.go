@@ -0,0 +1,205 @@
+// A facebook graph api client in go.
+// https://github.com/huandu/facebook/
+//
+// Copyright 2012, Huan Du
+// Licensed under the MIT license
+// https://github.com/huandu/facebook/blob/master/LICENSE
+
+package facebook
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const uploadSessionIDPrefix = "upload:"
+
+// UploadSession is an upload session created by the Resumable Upload API.
+//
+// Facebook document: https://developers.facebook.com/docs/graph-api/guides/upload
+type UploadSession struct {
+	ID string // upload session id in the form of "upload:<id>".
+
+	session *Session
+}
+
+// ResumableUpload uploads all data in source through the Resumable Upload API
+// and returns the file handle which can be used in other Graph API calls.
+//
+// The appID is the app which owns the upload session. If appID is empty,
+// the id of the App associated with this Session is used.
+//
+// ResumableUpload starts a new upload session every time it's called.
+// The upload session is returned even if upload fails after the session is started.
+// To resume an interrupted upload, call UploadSession#Upload again or
+// save UploadSession#ID and call ResumeUpload later.
+func (session *Session) ResumableUpload(appID, fileName string, source io.ReadSeeker, fileType string) (us *UploadSession, handle string, err error) {
+	var length int64
+	length, err = source.Seek(0, io.SeekEnd)
+
+	if err != nil {
+		err = fmt.Errorf("facebook: cannot get the length of upload source; %w", err)
+		return
+	}
+
+	us, err = session.StartUpload(appID, fileName, length, fileType)
+
+	if err != nil {
+		return
+	}
+
+	handle, err = us.Upload(source)
+	return
+}
+
+// StartUpload starts a new upload session.
+// The fileLength is the size of file in bytes and fileType is the MIME type of the file,
+// e.g. "image/jpeg".
+func (session *Session) StartUpload(appID, fileName string, fileLength int64, fileType string) (*UploadSession, error) {
+	if appID == "" {
+		if session.app == nil {
+			return nil, fmt.Errorf("facebook: app id is required to start an upload session")
+		}
+
+		appID = session.app.AppId
+	}
+
+	res, err := session.Post(fmt.Sprintf("/%v/uploads", appID), Params{
+		"file_name":   fileName,
+		"file_length": fileLength,
+		"file_type":   fileType,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	var id string
+	err = res.DecodeField("id", &id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return session.ResumeUpload(id), nil
+}
+
+// ResumeUpload returns an upload session by its id.
+// It's useful to resume an upload started by other process.
+func (session *Session) ResumeUpload(id string) *UploadSession {
+	if !strings.HasPrefix(id, uploadSessionIDPrefix) {
+		id = uploadSessionIDPrefix + id
+	}
+
+	return &UploadSession{
+		ID:      id,
+		session: session,
+	}
+}
+
+// Offset queries the number of bytes which have been received by facebook.
+func (us *UploadSession) Offset() (offset int64, err error) {
+	var request *http.Request
+	request, err = http.NewRequest("GET", us.session.getURL("graph", us.ID, nil), nil)
+
+	if err != nil {
+		return
+	}
+
+	var cred credential
+
+	if cred, err = us.authorize(request); err != nil {
+		return
+	}
+
+	var res Result
+	res, err = us.session.request(request, cred)
+
+	if err != nil {
+		return
+	}
+
+	err = res.DecodeField("file_offset", (*Int64)(&offset))
+	return
+}
+
+// Upload uploads data in source from current file offset and returns the file handle.
+//
+// Upload queries current file offset before uploading.
+// If an upload is interrupted, call Upload again with the same source to resume it.
+func (us *UploadSession) Upload(source io.ReadSeeker) (handle string, err error) {
+	var offset, length int64
+	offset, err = us.Offset()
+
+	if err != nil {
+		return
+	}
+
+	length, err = source.Seek(0, io.SeekEnd)
+
+	if err != nil {
+		err = fmt.Errorf("facebook: cannot get the length of upload source; %w", err)
+		return
+	}
+
+	if offset > length {
+		err = fmt.Errorf("facebook: file offset %v exceeds source length %v", offset, length)
+		return
+	}
+
+	_, err = source.Seek(offset, io.SeekStart)
+
+	if err != nil {
+		err = fmt.Errorf("facebook: cannot seek to file offset %v; %w", offset, err)
+		return
+	}
+
+	var request *http.Request
+	request, err = http.NewRequest("POST", us.session.getURL("graph", us.ID, nil), io.LimitReader(source, length-offset))
+
+	if err != nil {
+		return
+	}
+
+	request.ContentLength = length - offset
+	request.Header.Set("Content-Type", "application/octet-stream")
+	request.Header.Set("file_offset", strconv.FormatInt(offset, 10))
+
+	var cred credential
+
+	if cred, err = us.authorize(request); err != nil {
+		return
+	}
+
+	var res Result
+	res, err = us.session.request(request, cred)
+
+	if err != nil {
+		return
+	}
+
+	err = res.DecodeField("h", &handle)
+	return
+}
+
+func (us *UploadSession) authorize(request *http.Request) (cred credential, err error) {
+	if cred, err = us.session.resolveCredential(); err != nil {
+		return
+	}
+
+	// the Resumable Upload API requires access token in the "OAuth" scheme.
+	if cred.accessToken != "" {
+		request.Header.Set("Authorization", "OAuth "+cred.accessToken)
+	}
+
+	if cred.appsecretProof != "" {
+		query := request.URL.Query()
+		query.Set("appsecret_proof", cred.appsecretProof)
+		request.URL.RawQuery = query.Encode()
+	}
+
+	return
+}
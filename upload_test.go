@@ -0,0 +1,194 @@
+// A facebook graph api client in go.
+// https://github.com/huandu/facebook/
+//
+// Copyright 2012, Huan Du
+// Licensed under the MIT license
+// https://github.com/huandu/facebook/blob/master/LICENSE
+
+package facebook
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestResumableUpload(t *testing.T) {
+	const content = "0123456789abcdef"
+	const token = "upload-token"
+	received := ""
+
+	testMux := http.NewServeMux()
+	testMux.HandleFunc("/v3.0/123/uploads", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+
+		if r.Form.Get("file_length") != strconv.Itoa(len(content)) || r.Form.Get("file_name") != "a.txt" {
+			t.Errorf("invalid upload session params. [form:%v]", r.Form)
+		}
+
+		w.Write([]byte(`{"id":"upload:session-1"}`))
+	})
+	testMux.HandleFunc("/v3.0/upload:session-1", func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "OAuth "+token {
+			t.Errorf("invalid authorization header. [header:%v]", auth)
+		}
+
+		if r.Method == "GET" {
+			fmt.Fprintf(w, `{"id":"upload:session-1","file_offset":%v}`, len(received))
+			return
+		}
+
+		offset, _ := strconv.Atoi(r.Header.Get("file_offset"))
+
+		if offset != len(received) {
+			t.Errorf("invalid file offset. [expected:%v] [actual:%v]", len(received), offset)
+		}
+
+		data, _ := io.ReadAll(r.Body)
+		received += string(data)
+		w.Write([]byte(`{"h":"file-handle"}`))
+	})
+
+	srv := httptest.NewServer(testMux)
+	defer srv.Close()
+
+	app := New("123", "secret")
+	session := app.Session(token)
+	session.Version = "v3.0"
+	session.BaseURL = srv.URL + "/"
+
+	us, handle, err := session.ResumableUpload("", "a.txt", strings.NewReader(content), "text/plain")
+
+	if err != nil {
+		t.Fatalf("fail to upload. [e:%v]", err)
+	}
+
+	if us == nil || us.ID != "upload:session-1" {
+		t.Fatalf("invalid upload session. [us:%v]", us)
+	}
+
+	if handle != "file-handle" || received != content {
+		t.Fatalf("invalid upload result. [handle:%v] [received:%v]", handle, received)
+	}
+
+	// resume an upload which has received part of the content.
+	received = content[:10]
+	us = session.ResumeUpload("session-1")
+
+	if us.ID != "upload:session-1" {
+		t.Fatalf("invalid upload session id. [id:%v]", us.ID)
+	}
+
+	offset, err := us.Offset()
+
+	if err != nil || offset != 10 {
+		t.Fatalf("invalid file offset. [offset:%v] [e:%v]", offset, err)
+	}
+
+	handle, err = us.Upload(strings.NewReader(content))
+
+	if err != nil {
+		t.Fatalf("fail to resume upload. [e:%v]", err)
+	}
+
+	if handle != "file-handle" || received != content {
+		t.Fatalf("invalid resumed upload result. [handle:%v] [received:%v]", handle, received)
+	}
+}
+
+func TestResumableUploadWithAppsecretProof(t *testing.T) {
+	const token = "upload-token"
+	app := New("123", "secret")
+	session := app.Session(token)
+	session.EnableAppsecretProof(true)
+	session.UseAuthorizationHeader()
+	proof := session.AppsecretProof()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "OAuth "+token {
+			t.Errorf("upload api requires OAuth scheme. [header:%v]", auth)
+		}
+
+		if actual := r.URL.Query().Get("appsecret_proof"); actual != proof {
+			t.Errorf("invalid appsecret proof. [expected:%v] [actual:%v]", proof, actual)
+		}
+
+		if r.Method == "GET" {
+			w.Write([]byte(`{"id":"upload:session-1","file_offset":0}`))
+			return
+		}
+
+		w.Write([]byte(`{"h":"file-handle"}`))
+	}))
+	defer srv.Close()
+
+	session.Version = "v3.0"
+	session.BaseURL = srv.URL + "/"
+	handle, err := session.ResumeUpload("session-1").Upload(strings.NewReader("content"))
+
+	if err != nil || handle != "file-handle" {
+		t.Fatalf("fail to upload. [handle:%v] [e:%v]", handle, err)
+	}
+}
+
+func TestResumableUploadInterrupted(t *testing.T) {
+	const content = "0123456789abcdef"
+	failed := false
+	received := ""
+
+	testMux := http.NewServeMux()
+	testMux.HandleFunc("/v3.0/123/uploads", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":"upload:session-1"}`))
+	})
+	testMux.HandleFunc("/v3.0/upload:session-1", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			fmt.Fprintf(w, `{"id":"upload:session-1","file_offset":%v}`, len(received))
+			return
+		}
+
+		data, _ := io.ReadAll(r.Body)
+
+		// the first upload is interrupted after receiving part of the content.
+		if !failed {
+			failed = true
+			received += string(data[:10])
+			w.Write([]byte(`{"error":{"message":"interrupted","code":1}}`))
+			return
+		}
+
+		received += string(data)
+		w.Write([]byte(`{"h":"file-handle"}`))
+	})
+
+	srv := httptest.NewServer(testMux)
+	defer srv.Close()
+
+	app := New("123", "secret")
+	session := app.Session("upload-token")
+	session.Version = "v3.0"
+	session.BaseURL = srv.URL + "/"
+
+	us, _, err := session.ResumableUpload("", "a.txt", strings.NewReader(content), "text/plain")
+
+	if err == nil {
+		t.Fatalf("upload must fail.")
+	}
+
+	if us == nil || us.ID != "upload:session-1" {
+		t.Fatalf("upload session must be returned with error. [us:%v]", us)
+	}
+
+	handle, err := us.Upload(strings.NewReader(content))
+
+	if err != nil {
+		t.Fatalf("fail to resume upload. [e:%v]", err)
+	}
+
+	if handle != "file-handle" || received != content {
+		t.Fatalf("invalid resumed upload result. [handle:%v] [received:%v]", handle, received)
+	}
+}
@@ -0,0 +1,259 @@
+// A facebook graph api client in go.
+// https://github.com/huandu/facebook/
+//
+// Copyright 2012, Huan Du
+// Licensed under the MIT license
+// https://github.com/huandu/facebook/blob/master/LICENSE
+
+package facebook
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Graph API versions.
+const (
+	Version16_0 = "v16.0"
+	Version17_0 = "v17.0"
+	Version18_0 = "v18.0"
+	Version19_0 = "v19.0"
+	Version20_0 = "v20.0"
+	Version21_0 = "v21.0"
+	Version22_0 = "v22.0"
+	Version23_0 = "v23.0"
+)
+
+// MinVersionLifetime is the min duration facebook keeps a Graph API version available after its release.
+const MinVersionLifetime = 2 * 365 * 24 * time.Hour
+
+// Version warning types.
+const (
+	VersionUpgraded   VersionWarningType = iota + 1 // facebook serves a different version from the requested one.
+	VersionDeprecated                               // facebook reports a deprecation in debug messages.
+)
+
+// ErrUnknownVersion is returned by CheckVersion if a version is malformed or
+// older than the latest known version but not in APIVersions.
+var ErrUnknownVersion = errors.New("facebook: unknown graph api version")
+
+// matches a version like "v19.0" in deprecation messages.
+var regexpVersion = regexp.MustCompile(`v\d+\.\d+`)
+
+// APIVersion is a Graph API version with its release and sunset dates.
+//
+// Facebook document: https://developers.facebook.com/docs/graph-api/changelog/versions
+type APIVersion struct {
+	Version  string    // version string, e.g. "v19.0".
+	Released time.Time // release date.
+	Sunset   time.Time // the date when the version is no longer available. It's zero if it's not announced yet.
+}
+
+// APIVersions is the list of known Graph API versions in release order.
+var APIVersions = []APIVersion{
+	{Version16_0, versionDate(2023, 2, 2), versionDate(2025, 5, 14)},
+	{Version17_0, versionDate(2023, 5, 23), versionDate(2025, 9, 12)},
+	{Version18_0, versionDate(2023, 9, 12), versionDate(2026, 1, 26)},
+	{Version19_0, versionDate(2024, 1, 23), versionDate(2026, 5, 21)},
+	{Version20_0, versionDate(2024, 5, 21), versionDate(2026, 9, 24)},
+	{Version21_0, versionDate(2024, 10, 2), time.Time{}},
+	{Version22_0, versionDate(2025, 1, 21), time.Time{}},
+	{Version23_0, versionDate(2025, 5, 29), time.Time{}},
+}
+
+func versionDate(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// ExpectedSunset returns the sunset date. If it's not announced yet,
+// the earliest possible sunset date, which is MinVersionLifetime after release, is returned.
+func (v APIVersion) ExpectedSunset() time.Time {
+	if !v.Sunset.IsZero() {
+		return v.Sunset
+	}
+
+	return v.Released.Add(MinVersionLifetime)
+}
+
+// LookupVersion finds a known Graph API version.
+func LookupVersion(version string) (APIVersion, bool) {
+	for _, v := range APIVersions {
+		if v.Version == version {
+			return v, true
+		}
+	}
+
+	return APIVersion{}, false
+}
+
+// CheckVersion returns error if version is unknown or expires within d.
+// It's designed to fail CI before a version expires.
+//
+// A version newer than the latest one in APIVersions is released after the latest one,
+// so it's checked with the ExpectedSunset of the latest one as its earliest possible sunset date.
+// Returns an error wrapping ErrUnknownVersion if version is malformed or
+// it's an unknown version older than the latest one.
+//
+//	if err := facebook.CheckVersion(facebook.Version, 90*24*time.Hour); err != nil {
+//	    t.Fatal(err)
+//	}
+func CheckVersion(version string, d time.Duration) error {
+	v, ok := LookupVersion(version)
+
+	if !ok {
+		latest := APIVersions[len(APIVersions)-1]
+
+		if compareVersion(version, latest.Version) <= 0 {
+			return fmt.Errorf("%w '%v'", ErrUnknownVersion, version)
+		}
+
+		// the sunset of a newer version is unknown.
+		v = APIVersion{
+			Version:  version,
+			Released: latest.Released,
+		}
+	}
+
+	sunset := v.ExpectedSunset()
+
+	if time.Until(sunset) <= d {
+		return fmt.Errorf("facebook: graph api version %v expires at %v", version, sunset.Format("2006-01-02"))
+	}
+
+	return nil
+}
+
+// compareVersion compares two versions like "v19.0".
+// A malformed version is treated as the smallest version.
+func compareVersion(a, b string) int {
+	var aMajor, aMinor, bMajor, bMinor int
+
+	if !parseVersion(a, &aMajor, &aMinor) {
+		return -1
+	}
+
+	if !parseVersion(b, &bMajor, &bMinor) {
+		return 1
+	}
+
+	if aMajor != bMajor {
+		return aMajor - bMajor
+	}
+
+	return aMinor - bMinor
+}
+
+func parseVersion(version string, major, minor *int) bool {
+	_, err := fmt.Sscanf(version, "v%d.%d", major, minor)
+	return err == nil && fmt.Sprintf("v%d.%d", *major, *minor) == version
+}
+
+// VersionWarningType is the type of a VersionWarning.
+type VersionWarningType int
+
+// VersionWarning is reported by Session when facebook serves a different version
+// or reports a deprecation.
+type VersionWarning struct {
+	Type      VersionWarningType
+	Requested string // requested version. It's empty if version is not set.
+	Served    string // the version in facebook-api-version header.
+	Version   string // the version mentioned in deprecation message. It can be empty.
+	Message   string // deprecation message.
+	Link      string // document link of deprecation message.
+}
+
+// VersionWarningCallback is called when Session reports a VersionWarning.
+type VersionWarningCallback func(session *Session, warning *VersionWarning)
+
+type versionWatcher struct {
+	mu         sync.Mutex
+	callback   VersionWarningCallback
+	lastServed string
+}
+
+// OnVersionWarning sets a callback which is called when facebook serves a version
+// different from the requested one or reports a deprecation in debug messages.
+//
+// An upgrade is reported once until the served version changes again.
+// Deprecations are reported only when debug mode is on, e.g. SetDebug(DEBUG_WARNING),
+// because facebook sends them in debug messages.
+func (session *Session) OnVersionWarning(callback VersionWarningCallback) {
+	session.versionWatcher = &versionWatcher{
+		callback: callback,
+	}
+}
+
+// requestedVersion returns the version used in request url.
+func (session *Session) requestedVersion() string {
+	if session.Version != "" {
+		return session.Version
+	}
+
+	return Version
+}
+
+// checkVersion reports version warnings found in res and response.
+func (session *Session) checkVersion(res Result, response *http.Response) {
+	watcher := session.versionWatcher
+
+	if watcher == nil || watcher.callback == nil || response == nil {
+		return
+	}
+
+	requested := session.requestedVersion()
+	served := response.Header.Get(facebookAPIVersionHeader)
+	var warnings []*VersionWarning
+
+	watcher.mu.Lock()
+
+	if served != "" && requested != "" && served != requested && served != watcher.lastServed {
+		warnings = append(warnings, &VersionWarning{
+			Type:      VersionUpgraded,
+			Requested: requested,
+			Served:    served,
+			Message:   fmt.Sprintf("facebook serves graph api %v instead of %v", served, requested),
+		})
+	}
+
+	if served != "" {
+		watcher.lastServed = served
+	}
+
+	watcher.mu.Unlock()
+
+	if debugInfo := res.DebugInfo(); debugInfo != nil {
+		for _, msg := range debugInfo.Messages {
+			if !isDeprecationMessage(msg) {
+				continue
+			}
+
+			warnings = append(warnings, &VersionWarning{
+				Type:      VersionDeprecated,
+				Requested: requested,
+				Served:    served,
+				Version:   regexpVersion.FindString(msg.Message),
+				Message:   msg.Message,
+				Link:      msg.Link,
+			})
+		}
+	}
+
+	for _, warning := range warnings {
+		watcher.callback(session, warning)
+	}
+}
+
+func isDeprecationMessage(msg DebugMessage) bool {
+	if msg.Type != "warning" {
+		return false
+	}
+
+	message := strings.ToLower(msg.Message)
+	return strings.Contains(message, "deprecat") || strings.Contains(message, "no longer available") ||
+		strings.Contains(message, "will be removed")
+}
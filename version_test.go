@@ -0,0 +1,127 @@
+// A facebook graph api client in go.
+// https://github.com/huandu/facebook/
+//
+// Copyright 2012, Huan Du
+// Licensed under the MIT license
+// https://github.com/huandu/facebook/blob/master/LICENSE
+
+package facebook
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCheckVersion(t *testing.T) {
+	if _, ok := LookupVersion(Version19_0); !ok {
+		t.Fatalf("version must be known.")
+	}
+
+	for _, version := range []string{"v1.0", "19.0", "v19", "v19.0.1", ""} {
+		if err := CheckVersion(version, 0); !errors.Is(err, ErrUnknownVersion) {
+			t.Fatalf("unknown version must fail. [version:%v] [e:%v]", version, err)
+		}
+	}
+
+	if err := CheckVersion(Version16_0, 0); err == nil {
+		t.Fatalf("expired version must fail.")
+	}
+
+	latest := APIVersions[len(APIVersions)-1]
+	remaining := time.Until(latest.ExpectedSunset())
+
+	if remaining > 0 {
+		if err := CheckVersion(latest.Version, remaining-time.Hour); err != nil {
+			t.Fatalf("version must be available. [e:%v]", err)
+		}
+	}
+
+	if err := CheckVersion(latest.Version, remaining+time.Hour); err == nil {
+		t.Fatalf("version expiring soon must fail.")
+	}
+
+	// a version newer than the latest known one is not unknown.
+	newer := fmt.Sprintf("v%d.0", len(APIVersions)+100)
+
+	if remaining > 0 {
+		if err := CheckVersion(newer, remaining-time.Hour); err != nil {
+			t.Fatalf("newer version must be available. [e:%v]", err)
+		}
+	}
+
+	if err := CheckVersion(newer, remaining+time.Hour); err == nil || errors.Is(err, ErrUnknownVersion) {
+		t.Fatalf("newer version must be checked with the expected sunset of latest version. [e:%v]", err)
+	}
+
+	if !latest.Sunset.IsZero() || !latest.ExpectedSunset().Equal(latest.Released.Add(MinVersionLifetime)) {
+		t.Fatalf("expected sunset must be min lifetime after release. [v:%#v]", latest)
+	}
+
+	for i := 1; i < len(APIVersions); i++ {
+		if !APIVersions[i-1].Released.Before(APIVersions[i].Released) {
+			t.Fatalf("versions must be in release order. [v:%v]", APIVersions[i].Version)
+		}
+	}
+}
+
+func TestSessionVersionWarning(t *testing.T) {
+	served := "v20.0"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("facebook-api-version", served)
+
+		if r.URL.Query().Get("debug") != "" {
+			fmt.Fprint(w, `{"id":"123","__debug__":{"messages":[
+				{"type":"warning","message":"The 'foo' field is deprecated for versions v20.0 and higher","link":"https://developers.facebook.com/docs/"},
+				{"type":"info","message":"The 'bar' field is deprecated"}
+			]}}`)
+			return
+		}
+
+		fmt.Fprint(w, `{"id":"123"}`)
+	}))
+	defer server.Close()
+
+	var warnings []*VersionWarning
+	session := &Session{
+		BaseURL: server.URL + "/",
+		Version: "v19.0",
+	}
+	session.OnVersionWarning(func(s *Session, warning *VersionWarning) {
+		warnings = append(warnings, warning)
+	})
+
+	session.Get("/me", nil)
+	session.Get("/me", nil)
+
+	if len(warnings) != 1 {
+		t.Fatalf("upgrade must be reported once. [warnings:%v]", len(warnings))
+	}
+
+	if w := warnings[0]; w.Type != VersionUpgraded || w.Requested != "v19.0" || w.Served != "v20.0" {
+		t.Fatalf("invalid upgrade warning. [warning:%#v]", w)
+	}
+
+	session.Version = "v20.0"
+	session.SetDebug(DEBUG_WARNING)
+	session.Get("/me", nil)
+
+	if len(warnings) != 2 {
+		t.Fatalf("deprecation must be reported. [warnings:%v]", len(warnings))
+	}
+
+	if w := warnings[1]; w.Type != VersionDeprecated || w.Version != "v20.0" || w.Link == "" {
+		t.Fatalf("invalid deprecation warning. [warning:%#v]", w)
+	}
+
+	served = "v21.0"
+	session.SetDebug(DEBUG_OFF)
+	session.Get("/me", nil)
+
+	if len(warnings) != 3 || warnings[2].Served != "v21.0" {
+		t.Fatalf("new upgrade must be reported. [warnings:%v]", len(warnings))
+	}
+}
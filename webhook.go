@@ -0,0 +1,342 @@
+// A facebook graph api client in go.
+// https://github.com/huandu/facebook/
+//
+// Copyright 2012, Huan Du
+// Licensed under the MIT license
+// https://github.com/huandu/facebook/blob/master/LICENSE
+
+package facebook
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// DefaultWebhookMaxBodySize is the default max size of a webhook request body.
+const DefaultWebhookMaxBodySize = 10 << 20
+
+// Webhook signature HTTP headers.
+const (
+	webhookSignature256Header = "X-Hub-Signature-256"
+	webhookSignatureHeader    = "X-Hub-Signature"
+)
+
+var errEmptyWebhookSecret = errors.New("facebook: cannot verify webhook request signature without app secret")
+
+// WebhookEvent is the payload of a webhook notification.
+//
+// Facebook document: https://developers.facebook.com/docs/graph-api/webhooks/getting-started
+type WebhookEvent struct {
+	Object string         // object type, e.g. "page", "user", "instagram" or "whatsapp_business_account".
+	Entry  []WebhookEntry // changes of objects.
+}
+
+// WebhookEntry is a changed object in WebhookEvent.
+type WebhookEntry struct {
+	ID        string             // object id.
+	Time      Int64              // unix timestamp of the notification.
+	Changes   []WebhookChange    // changed fields.
+	Messaging []WebhookMessaging // messenger events.
+
+	Raw Result `facebook:"-"` // the entry payload.
+}
+
+// WebhookChange is a changed field of an object.
+type WebhookChange struct {
+	Field string      // field name, e.g. "feed".
+	Value interface{} `facebook:"-"` // new value. It's usually a map[string]interface{}.
+}
+
+// DecodeValue decodes change value to any type. See Result#DecodeField.
+func (change *WebhookChange) DecodeValue(v interface{}) error {
+	return Result{"value": change.Value}.DecodeField("value", v)
+}
+
+// WebhookMessaging is a messenger event.
+type WebhookMessaging struct {
+	Sender    WebhookParticipant
+	Recipient WebhookParticipant
+	Timestamp Int64  // unix timestamp in milliseconds.
+	Message   Result // set in "messages" event.
+	Postback  Result // set in "messaging_postbacks" event.
+
+	Raw Result `facebook:"-"` // the messaging payload. Read other events in it.
+}
+
+// WebhookParticipant is the sender or recipient of a messenger event.
+type WebhookParticipant struct {
+	ID string
+}
+
+// WebhookChangeHandler handles a changed field in webhook notification.
+type WebhookChangeHandler func(entry *WebhookEntry, change *WebhookChange) error
+
+// WebhookMessagingHandler handles a messenger event in webhook notification.
+type WebhookMessagingHandler func(entry *WebhookEntry, messaging *WebhookMessaging) error
+
+// WebhookHandler is an http.Handler receiving webhook notifications.
+//
+// It answers the verification request with VerifyToken, verifies
+// X-Hub-Signature-256 or X-Hub-Signature with app secret and dispatches
+// notifications to handlers registered by HandleChange or HandleMessaging.
+//
+// If any handler returns error, WebhookHandler responds with status 500 so that
+// facebook will retry the notification later.
+type WebhookHandler struct {
+	VerifyToken string // the token set when subscribing webhooks.
+	MaxBodySize int64  // max size of a request body. DefaultWebhookMaxBodySize is used if it's 0.
+
+	// OnError is called when a request is rejected or a handler returns error. It can be nil.
+	OnError func(r *http.Request, err error)
+
+	app               *App
+	mu                sync.RWMutex
+	changeHandlers    map[string]map[string][]WebhookChangeHandler
+	messagingHandlers map[string][]WebhookMessagingHandler
+}
+
+var _ http.Handler = &WebhookHandler{}
+
+// WebhookHandler creates a WebhookHandler verifying notifications with app secret.
+func (app *App) WebhookHandler(verifyToken string) *WebhookHandler {
+	return &WebhookHandler{
+		VerifyToken:       verifyToken,
+		app:               app,
+		changeHandlers:    map[string]map[string][]WebhookChangeHandler{},
+		messagingHandlers: map[string][]WebhookMessagingHandler{},
+	}
+}
+
+// HandleChange registers a handler for changes of field in object, e.g. "page" and "feed".
+// If field is empty, handler is called for all fields of the object.
+func (h *WebhookHandler) HandleChange(object, field string, handler WebhookChangeHandler) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	fields := h.changeHandlers[object]
+
+	if fields == nil {
+		fields = map[string][]WebhookChangeHandler{}
+		h.changeHandlers[object] = fields
+	}
+
+	fields[field] = append(fields[field], handler)
+}
+
+// HandleMessaging registers a handler for messenger events of object, e.g. "page" or "instagram".
+func (h *WebhookHandler) HandleMessaging(object string, handler WebhookMessagingHandler) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.messagingHandlers[object] = append(h.messagingHandlers[object], handler)
+}
+
+// ServeHTTP implements http.Handler.
+func (h *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		h.serveVerification(w, r)
+
+	case "POST":
+		h.serveNotification(w, r)
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *WebhookHandler) serveVerification(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	if query.Get("hub.mode") != "subscribe" || h.VerifyToken == "" ||
+		!hmac.Equal([]byte(query.Get("hub.verify_token")), []byte(h.VerifyToken)) {
+		h.reportError(r, fmt.Errorf("facebook: invalid webhook verification request"))
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	io.WriteString(w, query.Get("hub.challenge"))
+}
+
+func (h *WebhookHandler) serveNotification(w http.ResponseWriter, r *http.Request) {
+	maxBodySize := h.MaxBodySize
+
+	if maxBodySize == 0 {
+		maxBodySize = DefaultWebhookMaxBodySize
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+
+	if err != nil {
+		h.reportError(r, fmt.Errorf("facebook: cannot read webhook request body; %w", err))
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if err = h.app.VerifyWebhookSignature(body, r.Header); err != nil {
+		h.reportError(r, err)
+
+		// it's a server misconfiguration rather than a bad request.
+		if errors.Is(err, errEmptyWebhookSecret) {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	event, err := ParseWebhookEvent(body)
+
+	if err != nil {
+		h.reportError(r, err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if err = h.dispatch(event); err != nil {
+		h.reportError(r, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *WebhookHandler) dispatch(event *WebhookEvent) error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	fields := h.changeHandlers[event.Object]
+	messagingHandlers := h.messagingHandlers[event.Object]
+
+	for i := range event.Entry {
+		entry := &event.Entry[i]
+
+		for j := range entry.Changes {
+			change := &entry.Changes[j]
+			for _, field := range []string{change.Field, ""} {
+				for _, handler := range fields[field] {
+					if err := handler(entry, change); err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		for j := range entry.Messaging {
+			for _, handler := range messagingHandlers {
+				if err := handler(entry, &entry.Messaging[j]); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (h *WebhookHandler) reportError(r *http.Request, err error) {
+	if h.OnError != nil {
+		h.OnError(r, err)
+	}
+}
+
+// VerifyWebhookSignature verifies the signature of a webhook request body in header.
+// X-Hub-Signature-256 is preferred. The legacy X-Hub-Signature is used only if
+// X-Hub-Signature-256 is absent.
+//
+// Returns error if app secret is empty, as anyone can sign a request without secret.
+func (app *App) VerifyWebhookSignature(body []byte, header http.Header) error {
+	if app.AppSecret == "" {
+		return errEmptyWebhookSecret
+	}
+
+	var prefix, sig string
+	var newHash func() hash.Hash
+
+	if sig = header.Get(webhookSignature256Header); sig != "" {
+		prefix = "sha256="
+		newHash = sha256.New
+	} else if sig = header.Get(webhookSignatureHeader); sig != "" {
+		prefix = "sha1="
+		newHash = sha1.New
+	} else {
+		return fmt.Errorf("facebook: webhook request is not signed")
+	}
+
+	if !strings.HasPrefix(sig, prefix) {
+		return fmt.Errorf("facebook: webhook request signature uses an unknown method; expect '%v'", prefix)
+	}
+
+	expected, err := hex.DecodeString(sig[len(prefix):])
+
+	if err != nil {
+		return fmt.Errorf("facebook: webhook request signature is not a valid hex string")
+	}
+
+	mac := hmac.New(newHash, []byte(app.AppSecret))
+	mac.Write(body)
+
+	if !hmac.Equal(expected, mac.Sum(nil)) {
+		return fmt.Errorf("facebook: bad webhook request signature")
+	}
+
+	return nil
+}
+
+// ParseWebhookEvent parses a webhook request body.
+// It doesn't verify signature. Use App#VerifyWebhookSignature to do so.
+func ParseWebhookEvent(body []byte) (*WebhookEvent, error) {
+	res, err := MakeResult(body)
+
+	if err != nil {
+		return nil, fmt.Errorf("facebook: fail to parse webhook event; %w", err)
+	}
+
+	event := &WebhookEvent{}
+
+	if err = res.Decode(event); err != nil {
+		return nil, fmt.Errorf("facebook: fail to decode webhook event; %w", err)
+	}
+
+	var entries []Result
+	res.DecodeField("entry", &entries)
+
+	for i := range event.Entry {
+		if i >= len(entries) {
+			break
+		}
+
+		entry := &event.Entry[i]
+		entry.Raw = entries[i]
+
+		var changes, messaging []Result
+		entry.Raw.DecodeField("changes", &changes)
+		entry.Raw.DecodeField("messaging", &messaging)
+
+		for j := range entry.Changes {
+			if j < len(changes) {
+				entry.Changes[j].Value = changes[j]["value"]
+			}
+		}
+
+		for j := range entry.Messaging {
+			if j < len(messaging) {
+				entry.Messaging[j].Raw = messaging[j]
+			}
+		}
+	}
+
+	return event, nil
+}
@@ -0,0 +1,181 @@
+// A facebook graph api client in go.
+// https://github.com/huandu/facebook/
+//
+// Copyright 2012, Huan Du
+// Licensed under the MIT license
+// https://github.com/huandu/facebook/blob/master/LICENSE
+
+package facebook
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testWebhookPayload = `{
+	"object": "page",
+	"entry": [{
+		"id": "111",
+		"time": 1600000000,
+		"changes": [{
+			"field": "feed",
+			"value": {"item": "post", "verb": "add", "post_id": "111_222"}
+		}, {
+			"field": "name",
+			"value": "new name"
+		}]
+	}, {
+		"id": "111",
+		"time": 1600000001,
+		"messaging": [{
+			"sender": {"id": "333"},
+			"recipient": {"id": "111"},
+			"timestamp": 1600000001000,
+			"message": {"mid": "m_1", "text": "hello"}
+		}]
+	}]
+}`
+
+func signWebhookPayload(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestWebhookHandlerVerification(t *testing.T) {
+	handler := New("123", "secret").WebhookHandler("verify-me")
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/webhook?hub.mode=subscribe&hub.verify_token=verify-me&hub.challenge=1158201444", nil))
+
+	if w.Code != http.StatusOK || w.Body.String() != "1158201444" {
+		t.Fatalf("verification must echo challenge. [code:%v] [body:%v]", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/webhook?hub.mode=subscribe&hub.verify_token=wrong&hub.challenge=1158201444", nil))
+
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("verification with wrong token must be rejected. [code:%v]", w.Code)
+	}
+}
+
+func TestWebhookHandlerNotification(t *testing.T) {
+	app := New("123", "secret")
+	handler := app.WebhookHandler("verify-me")
+
+	var feeds, all []*WebhookChange
+	var messages []*WebhookMessaging
+	handler.HandleChange("page", "feed", func(entry *WebhookEntry, change *WebhookChange) error {
+		feeds = append(feeds, change)
+		return nil
+	})
+	handler.HandleChange("page", "", func(entry *WebhookEntry, change *WebhookChange) error {
+		all = append(all, change)
+		return nil
+	})
+	handler.HandleChange("user", "", func(entry *WebhookEntry, change *WebhookChange) error {
+		t.Errorf("user handler must not be called.")
+		return nil
+	})
+	handler.HandleMessaging("page", func(entry *WebhookEntry, messaging *WebhookMessaging) error {
+		messages = append(messages, messaging)
+		return nil
+	})
+
+	request := httptest.NewRequest("POST", "/webhook", strings.NewReader(testWebhookPayload))
+	request.Header.Set("X-Hub-Signature-256", signWebhookPayload("secret", testWebhookPayload))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, request)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("notification must be accepted. [code:%v]", w.Code)
+	}
+
+	if len(feeds) != 1 || len(all) != 2 || len(messages) != 1 {
+		t.Fatalf("handlers must be called. [feeds:%v] [all:%v] [messages:%v]", feeds, all, messages)
+	}
+
+	var feed struct {
+		Item   string
+		PostID string `facebook:"post_id"`
+	}
+	feeds[0].DecodeValue(&feed)
+
+	if feed.PostID != "111_222" || feed.Item != "post" || all[1].Value != "new name" {
+		t.Fatalf("invalid change values. [feed:%v] [name:%v]", feed, all[1].Value)
+	}
+
+	if msg := messages[0]; msg.Sender.ID != "333" || msg.Timestamp != 1600000001000 || msg.Message["text"] != "hello" || msg.Raw["recipient"] == nil {
+		t.Fatalf("invalid messaging. [messaging:%#v]", msg)
+	}
+
+	// bad signature.
+	request = httptest.NewRequest("POST", "/webhook", strings.NewReader(testWebhookPayload))
+	request.Header.Set("X-Hub-Signature-256", signWebhookPayload("wrong", testWebhookPayload))
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, request)
+
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("notification with bad signature must be rejected. [code:%v]", w.Code)
+	}
+
+	// legacy signature.
+	mac := hmac.New(sha1.New, []byte("secret"))
+	mac.Write([]byte(testWebhookPayload))
+	header := http.Header{}
+	header.Set("X-Hub-Signature", "sha1="+hex.EncodeToString(mac.Sum(nil)))
+
+	if err := app.VerifyWebhookSignature([]byte(testWebhookPayload), header); err != nil {
+		t.Fatalf("legacy signature must be verified. [e:%v]", err)
+	}
+
+	// handler error.
+	handler.HandleChange("page", "name", func(entry *WebhookEntry, change *WebhookChange) error {
+		return errors.New("fail")
+	})
+	request = httptest.NewRequest("POST", "/webhook", strings.NewReader(testWebhookPayload))
+	request.Header.Set("X-Hub-Signature-256", signWebhookPayload("secret", testWebhookPayload))
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, request)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("handler error must cause status 500. [code:%v]", w.Code)
+	}
+}
+
+func TestWebhookWithoutAppSecret(t *testing.T) {
+	app := New("123", "")
+	header := http.Header{}
+	header.Set("X-Hub-Signature-256", signWebhookPayload("", testWebhookPayload))
+
+	if err := app.VerifyWebhookSignature([]byte(testWebhookPayload), header); err == nil {
+		t.Fatalf("signature must not be verified without app secret.")
+	}
+
+	var reported error
+	handler := app.WebhookHandler("verify-me")
+	handler.OnError = func(r *http.Request, err error) {
+		reported = err
+	}
+	handler.HandleChange("page", "", func(entry *WebhookEntry, change *WebhookChange) error {
+		t.Errorf("handler must not be called.")
+		return nil
+	})
+
+	request := httptest.NewRequest("POST", "/webhook", strings.NewReader(testWebhookPayload))
+	request.Header = header
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, request)
+
+	if w.Code != http.StatusInternalServerError || reported == nil {
+		t.Fatalf("notification must be rejected without app secret. [code:%v] [e:%v]", w.Code, reported)
+	}
+}